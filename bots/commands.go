@@ -0,0 +1,122 @@
+package bots
+
+import (
+	"banter/models"
+	"banter/repositories"
+	"banter/utils/config"
+	"banter/webhooks"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var commandPattern = regexp.MustCompile(`^/([a-zA-Z0-9_-]{1,32})(?:\s+(.*))?$`)
+
+// Command is a slash command typed into a conversation, e.g. "/standup today"
+type Command struct {
+	Name string
+	Args string
+}
+
+// CommandPayload is posted to the webhook of every bot in the conversation that handles the command
+type CommandPayload struct {
+	Type           string    `json:"type"`
+	Command        string    `json:"command"`
+	Args           string    `json:"args"`
+	MessageID      uuid.UUID `json:"message_id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	SentAt         time.Time `json:"sent_at"`
+}
+
+// ParseCommand extracts the slash command from a message, if it is one
+func ParseCommand(content string) (Command, bool) {
+	matches := commandPattern.FindStringSubmatch(strings.TrimSpace(content))
+	if matches == nil {
+		return Command{}, false
+	}
+	return Command{Name: strings.ToLower(matches[1]), Args: strings.TrimSpace(matches[2])}, true
+}
+
+// DispatchCommand forwards a slash command message to the bots in its conversation that registered
+// the command. Bots answer by posting to the conversation with their API token.
+func DispatchCommand(ctx context.Context, store repositories.Store, message *models.Message) {
+	command, ok := ParseCommand(message.Content)
+	if !ok {
+		return
+	}
+
+	bots, err := store.Bots().ListForCommand(ctx, message.ConversationID, command.Name)
+	if err != nil {
+		slog.Error("Failed to fetch bots for command", "command", command.Name, "error", err)
+		return
+	}
+	if len(bots) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(CommandPayload{
+		Type:           "command",
+		Command:        command.Name,
+		Args:           command.Args,
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		SentAt:         message.CreatedAt,
+	})
+	if err != nil {
+		slog.Error("Failed to encode command", "command", command.Name, "error", err)
+		return
+	}
+
+	client := &http.Client{Timeout: commandTimeout()}
+	for i := range bots {
+		if err := sendCommand(ctx, client, &bots[i], body); err != nil {
+			slog.Warn("Failed to forward command to bot", "command", command.Name, "bot", bots[i].User.Username, "error", err)
+		}
+	}
+}
+
+// sendCommand posts the command to the bot's webhook, signed the same way as event webhooks
+func sendCommand(ctx context.Context, client *http.Client, bot *models.Bot, body []byte) error {
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, bot.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "banter-bots")
+	request.Header.Set("X-Banter-Event", "bot.command")
+	request.Header.Set("X-Banter-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Banter-Signature", webhooks.Sign(bot.WebhookSecret, timestamp, body))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("bot webhook responded with %d", response.StatusCode)
+	}
+	return nil
+}
+
+func commandTimeout() time.Duration {
+	if config.Configs.Bots.CommandTimeoutInSecs <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(config.Configs.Bots.CommandTimeoutInSecs) * time.Second
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"banter/utils/config"
+	"fmt"
+	"os"
+)
+
+const configUsage = `Usage: banter [-config path] config <command>
+
+Commands:
+  validate       load the configuration from every source and report every problem found`
+
+// runConfigCommand runs a config subcommand and exits the process on failure
+func runConfigCommand(path string, args []string) {
+	if len(args) != 1 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		os.Exit(2)
+	}
+
+	if _, err := config.Load(path); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("Configuration is valid")
+}
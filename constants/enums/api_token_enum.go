@@ -0,0 +1,10 @@
+package enums
+
+type TokenScope string
+
+const (
+	// ScopeRead allows read-only requests (GET, HEAD, OPTIONS)
+	ScopeRead TokenScope = "read"
+	// ScopeWrite allows every other request, e.g. posting messages
+	ScopeWrite TokenScope = "write"
+)
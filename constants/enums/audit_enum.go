@@ -0,0 +1,28 @@
+package enums
+
+type AuditAction string
+
+const (
+	AuditUserLogin              AuditAction = "user.login"
+	AuditUserCreatedAdmin       AuditAction = "user.admin_created"
+	AuditUserLoginFailed        AuditAction = "user.login_failed"
+	AuditUserUpdated            AuditAction = "user.updated"
+	AuditUserBanned             AuditAction = "user.banned"
+	AuditUserUnbanned           AuditAction = "user.unbanned"
+	AuditUserDeactivated        AuditAction = "user.deactivated"
+	AuditUserLoggedOut          AuditAction = "user.logged_out"
+	AuditUserPasswordResetForce AuditAction = "user.password_reset_forced"
+	AuditUserPasswordReset      AuditAction = "user.password_reset"
+	AuditUserErased             AuditAction = "user.erased"
+	AuditAccountDeletionRequest AuditAction = "account.deletion_requested"
+	AuditAccountDeletionCancel  AuditAction = "account.deletion_cancelled"
+	AuditAccountExportRequest   AuditAction = "account.export_requested"
+	AuditConversationDeleted    AuditAction = "conversation.deleted"
+	AuditMemberAdded            AuditAction = "conversation.member_added"
+	AuditMemberRemoved          AuditAction = "conversation.member_removed"
+	AuditBotCreated             AuditAction = "bot.created"
+	AuditBotUpdated             AuditAction = "bot.updated"
+	AuditBotDeleted             AuditAction = "bot.deleted"
+	AuditApiTokenCreated        AuditAction = "bot.token_created"
+	AuditApiTokenRevoked        AuditAction = "bot.token_revoked"
+)
@@ -0,0 +1,9 @@
+package enums
+
+type ConversationFilter string
+
+const (
+	ConversationsActive   ConversationFilter = "active"
+	ConversationsArchived ConversationFilter = "archived"
+	ConversationsAll      ConversationFilter = "all"
+)
@@ -0,0 +1,9 @@
+package enums
+
+type DevicePlatform string
+
+const (
+	PlatformAndroid DevicePlatform = "android"
+	PlatformIOS     DevicePlatform = "ios"
+	PlatformWeb     DevicePlatform = "web"
+)
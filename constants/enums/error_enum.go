@@ -0,0 +1,47 @@
+package enums
+
+// ErrorCode identifies a failure in API responses. Unlike the error title and message, codes never
+// change once published, so clients should match on them.
+type ErrorCode string
+
+const (
+	CodeInvalidInput          ErrorCode = "INVALID_INPUT"
+	CodeValidationFailed      ErrorCode = "VALIDATION_FAILED"
+	CodeInvalidID             ErrorCode = "INVALID_ID"
+	CodeInvalidCursor         ErrorCode = "INVALID_CURSOR"
+	CodeInvalidFilter         ErrorCode = "INVALID_FILTER"
+	CodeUnauthorized          ErrorCode = "UNAUTHORIZED"
+	CodeInvalidCredentials    ErrorCode = "INVALID_CREDENTIALS"
+	CodeInvalidToken          ErrorCode = "INVALID_TOKEN"
+	CodeSessionRevoked        ErrorCode = "SESSION_REVOKED"
+	CodeAccountInactive       ErrorCode = "ACCOUNT_INACTIVE"
+	CodeAccountBanned         ErrorCode = "ACCOUNT_BANNED"
+	CodeForbidden             ErrorCode = "FORBIDDEN"
+	CodeOriginNotAllowed      ErrorCode = "ORIGIN_NOT_ALLOWED"
+	CodeMethodNotAllowed      ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeInsufficientScope     ErrorCode = "INSUFFICIENT_SCOPE"
+	CodePasswordResetRequired ErrorCode = "PASSWORD_RESET_REQUIRED"
+	CodeNotFound              ErrorCode = "NOT_FOUND"
+	CodeRouteNotFound         ErrorCode = "ROUTE_NOT_FOUND"
+	CodeConflict              ErrorCode = "CONFLICT"
+	CodeRequestTimeout        ErrorCode = "REQUEST_TIMEOUT"
+	CodeBodyTooLarge          ErrorCode = "BODY_TOO_LARGE"
+	CodeRateLimited           ErrorCode = "RATE_LIMITED"
+	CodeInternalError         ErrorCode = "INTERNAL_ERROR"
+
+	CodeConversationNotFound ErrorCode = "CONVERSATION_NOT_FOUND"
+	CodeUserNotFound         ErrorCode = "USER_NOT_FOUND"
+	CodeMemberNotFound       ErrorCode = "MEMBER_NOT_FOUND"
+	CodeNotAMember           ErrorCode = "NOT_A_MEMBER"
+	CodeAlreadyAMember       ErrorCode = "ALREADY_A_MEMBER"
+	CodeGroupTooSmall        ErrorCode = "GROUP_TOO_SMALL"
+	CodeTooFewMembers        ErrorCode = "TOO_FEW_MEMBERS"
+	CodeBotNotFound          ErrorCode = "BOT_NOT_FOUND"
+	CodeTokenNotFound        ErrorCode = "TOKEN_NOT_FOUND"
+	CodeWebhookNotFound      ErrorCode = "WEBHOOK_NOT_FOUND"
+	CodeDeliveryNotFound     ErrorCode = "DELIVERY_NOT_FOUND"
+	CodeDeviceNotFound       ErrorCode = "DEVICE_NOT_FOUND"
+	CodeExportNotFound       ErrorCode = "EXPORT_NOT_FOUND"
+	CodeExportNotReady       ErrorCode = "EXPORT_NOT_READY"
+	CodeDeletionNotScheduled ErrorCode = "DELETION_NOT_SCHEDULED"
+)
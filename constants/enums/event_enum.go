@@ -0,0 +1,22 @@
+package enums
+
+// EventType names a domain event. Events are written to the outbox and fanned out to consumers
+// such as webhooks.
+type EventType string
+
+const (
+	EventConversationCreated EventType = "conversation.created"
+	EventConversationDeleted EventType = "conversation.deleted"
+	EventMemberAdded         EventType = "conversation.member_added"
+	EventMemberRemoved       EventType = "conversation.member_removed"
+	EventMessageCreated      EventType = "message.created"
+)
+
+// AllEventTypes lists every domain event type
+var AllEventTypes = []EventType{
+	EventConversationCreated,
+	EventConversationDeleted,
+	EventMemberAdded,
+	EventMemberRemoved,
+	EventMessageCreated,
+}
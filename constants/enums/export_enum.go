@@ -0,0 +1,11 @@
+package enums
+
+type ExportStatus string
+
+const (
+	ExportPending    ExportStatus = "pending"
+	ExportProcessing ExportStatus = "processing"
+	ExportCompleted  ExportStatus = "completed"
+	ExportFailed     ExportStatus = "failed"
+	ExportExpired    ExportStatus = "expired"
+)
@@ -7,3 +7,10 @@ const (
 	UserInactive UserStatus = "inactive"
 	UserBanned   UserStatus = "banned"
 )
+
+type UserType string
+
+const (
+	UserTypeHuman UserType = "human"
+	UserTypeBot   UserType = "bot"
+)
@@ -0,0 +1,12 @@
+package enums
+
+// WebhookAllEvents subscribes a webhook to every event type
+const WebhookAllEvents EventType = "*"
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"banter/constants/enums"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is a domain event as carried by a broker
+type Message struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          enums.EventType `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Handler processes a message delivered by a broker
+type Handler func(ctx context.Context, message Message) error
+
+// Broker carries events from the outbox relay to their consumers. Delivery is at least once, a
+// message a consumer failed on is delivered again, so consumers should be wrapped with Idempotent.
+type Broker interface {
+	// Publish hands the message to the broker, returning once the broker has accepted it
+	Publish(ctx context.Context, message Message) error
+	// Subscribe registers a consumer for an event type. Replicas subscribing under the same
+	// consumer name share the messages between them.
+	Subscribe(eventType enums.EventType, consumer string, handler Handler) error
+	// Ping checks the broker can accept messages right now
+	Ping(ctx context.Context) error
+	// Close stops delivering messages to subscribers
+	Close() error
+}
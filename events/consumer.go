@@ -0,0 +1,27 @@
+package events
+
+import (
+	"banter/repositories"
+	"context"
+)
+
+// Idempotent wraps a handler so an event redelivered after the consumer handled it is skipped.
+// The event is marked as processed after the handler succeeds, so a crash in between still
+// redelivers it and handlers should tolerate that rare duplicate. Processed events are recorded in
+// the given store.
+func Idempotent(store repositories.Store, consumer string, handler Handler) Handler {
+	return func(ctx context.Context, message Message) error {
+		processed, err := store.Events().IsProcessed(ctx, consumer, message.ID)
+		if err != nil {
+			return err
+		}
+		if processed {
+			return nil
+		}
+
+		if err := handler(ctx, message); err != nil {
+			return err
+		}
+		return store.Events().MarkProcessed(ctx, consumer, message.ID)
+	}
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"banter/constants/enums"
+	"banter/repositories"
+	"banter/utils/config"
+	"banter/utils/logger"
+	"banter/webhooks"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var broker Broker
+
+// Setup connects to the configured broker, subscribes the consumers and starts the outbox relay,
+// which runs until ctx is cancelled. Outbox events are read from and processed events recorded in the
+// given store.
+func Setup(ctx context.Context, wg *sync.WaitGroup, store repositories.Store) {
+	cfg := config.Configs.Events
+
+	switch cfg.Broker {
+	case "nats":
+		natsBroker, err := NewNATSBroker(cfg.Nats, time.Duration(cfg.RetentionInDays)*24*time.Hour)
+		if err != nil {
+			logger.Logger.Fatalf("Failed to connect to NATS at %s: %v", cfg.Nats.Url, err)
+		}
+		broker = natsBroker
+	default:
+		broker = NewMemoryBroker()
+	}
+
+	for _, eventType := range enums.AllEventTypes {
+		if err := broker.Subscribe(eventType, "webhooks", Idempotent(store, "webhooks", publishWebhook(store))); err != nil {
+			logger.Logger.Fatalf("Failed to subscribe webhooks to %s: %v", eventType, err)
+		}
+	}
+
+	StartRelay(ctx, wg, broker, store)
+}
+
+// Ping checks the broker is connected
+func Ping(ctx context.Context) error {
+	if broker == nil {
+		return errors.New("broker is not set up")
+	}
+	return broker.Ping(ctx)
+}
+
+// Close disconnects from the broker, consumers finish the events they are handling first. Call it
+// once the relay has stopped.
+func Close() error {
+	if broker == nil {
+		return nil
+	}
+	return broker.Close()
+}
+
+// GetBroker returns the broker events are published to
+func GetBroker() Broker {
+	return broker
+}
+
+// publishWebhook returns the handler that schedules deliveries of an event to the webhook subscriptions
+// listening to it
+func publishWebhook(store repositories.Store) Handler {
+	return func(ctx context.Context, message Message) error {
+		return webhooks.PublishEvent(ctx, store, webhooks.Event{
+			ID:        message.ID,
+			Type:      message.Type,
+			CreatedAt: message.CreatedAt,
+			Data:      message.Payload,
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"banter/constants/enums"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MemoryBroker delivers messages to in-process subscribers. Publish runs the handlers before it
+// returns and fails if any of them fails, so the relay retries the message and consumers that
+// already handled it skip it.
+type MemoryBroker struct {
+	mu            sync.RWMutex
+	subscriptions map[enums.EventType][]memorySubscription
+	closed        bool
+}
+
+type memorySubscription struct {
+	consumer string
+	handler  Handler
+}
+
+// NewMemoryBroker creates a broker for a single server instance
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscriptions: map[enums.EventType][]memorySubscription{}}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, message Message) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return errors.New("broker is closed")
+	}
+	subscriptions := b.subscriptions[message.Type]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		if err := subscription.handler(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", subscription.consumer, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *MemoryBroker) Subscribe(eventType enums.EventType, consumer string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, subscription := range b.subscriptions[eventType] {
+		if subscription.consumer == consumer {
+			return fmt.Errorf("consumer %s is already subscribed to %s", consumer, eventType)
+		}
+	}
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], memorySubscription{consumer: consumer, handler: handler})
+	return nil
+}
+
+func (b *MemoryBroker) Ping(ctx context.Context) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return errors.New("broker is closed")
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	return nil
+}
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"banter/constants/enums"
+	"banter/utils/config"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	natsSetupTimeout = 10 * time.Second
+	natsMaxRetryWait = 5 * time.Minute
+)
+
+// natsRetryDelay is the wait before the first redelivery, doubled for every further one. Tests shorten it.
+var natsRetryDelay = time.Second
+
+// NATSBroker publishes messages to a NATS JetStream stream on the subject <prefix>.<event type>.
+// Each consumer reads through a durable JetStream consumer named after it, so replicas share the
+// messages and each one is handled by one replica. Messages are acknowledged once the handler
+// succeeds, failures are redelivered with exponential backoff until max deliver attempts were made.
+type NATSBroker struct {
+	conn       *nats.Conn
+	js         jetstream.JetStream
+	prefix     string
+	stream     string
+	maxDeliver int
+
+	mu        sync.Mutex
+	consuming []jetstream.ConsumeContext
+}
+
+// NewNATSBroker connects to the NATS server and creates the stream keeping the events, or updates it
+// to the given settings. Events are kept for the given retention even when every consumer has
+// acknowledged them.
+func NewNATSBroker(cfg config.NatsConfig, retention time.Duration) (*NATSBroker, error) {
+	conn, err := nats.Connect(cfg.Url,
+		nats.Name("banter"),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	prefix := cfg.SubjectPrefix
+	if prefix == "" {
+		prefix = "banter.events"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), natsSetupTimeout)
+	defer cancel()
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.Stream,
+		Subjects: []string{prefix + ".>"},
+		MaxAge:   retention,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &NATSBroker{conn: conn, js: js, prefix: prefix, stream: cfg.Stream, maxDeliver: cfg.MaxDeliver}, nil
+}
+
+// Publish returns once the stream has stored the message. The event ID is sent as the message ID,
+// so the stream drops a message the relay publishes again after failing to mark it published.
+func (b *NATSBroker) Publish(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = b.js.Publish(ctx, b.subject(message.Type), data, jetstream.WithMsgID(message.ID.String()))
+	return err
+}
+
+func (b *NATSBroker) Subscribe(eventType enums.EventType, consumer string, handler Handler) error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsSetupTimeout)
+	defer cancel()
+
+	// Durable names may not contain dots, which event types use
+	durable := consumer + "_" + strings.ReplaceAll(string(eventType), ".", "_")
+	durableConsumer, err := b.js.CreateOrUpdateConsumer(ctx, b.stream, jetstream.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: b.subject(eventType),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		MaxDeliver:    b.maxDeliver,
+	})
+	if err != nil {
+		return err
+	}
+
+	consuming, err := durableConsumer.Consume(func(msg jetstream.Msg) {
+		b.handle(consumer, msg, handler)
+	})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.consuming = append(b.consuming, consuming)
+	b.mu.Unlock()
+	return nil
+}
+
+// handle runs the handler on a delivered message and tells the server whether to redeliver it
+func (b *NATSBroker) handle(consumer string, msg jetstream.Msg, handler Handler) {
+	var message Message
+	if err := json.Unmarshal(msg.Data(), &message); err != nil {
+		slog.Error("Consumer dropped a malformed message", "consumer", consumer, "subject", msg.Subject(), "error", err)
+		if err := msg.Term(); err != nil {
+			slog.Warn("Failed to terminate message", "consumer", consumer, "error", err)
+		}
+		return
+	}
+
+	err := handler(context.Background(), message)
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			// The server redelivers it once the ack wait passes, Idempotent skips it then
+			slog.Warn("Failed to acknowledge event", "consumer", consumer, "event_id", message.ID, "error", err)
+		}
+		return
+	}
+
+	attempt := 1
+	if metadata, metadataErr := msg.Metadata(); metadataErr == nil {
+		attempt = int(metadata.NumDelivered)
+	}
+	if attempt >= b.maxDeliver {
+		slog.Error("Giving up on event", "consumer", consumer, "event_id", message.ID, "attempts", attempt, "error", err)
+		if err := msg.Term(); err != nil {
+			slog.Warn("Failed to terminate message", "consumer", consumer, "error", err)
+		}
+		return
+	}
+
+	slog.Warn("Consumer failed to handle event, retrying", "consumer", consumer, "event_id", message.ID, "attempt", attempt, "error", err)
+	if err := msg.NakWithDelay(natsBackoff(attempt)); err != nil {
+		slog.Warn("Failed to request redelivery", "consumer", consumer, "event_id", message.ID, "error", err)
+	}
+}
+
+// natsBackoff returns how long to wait before redelivering a message that failed the given attempt
+func natsBackoff(attempt int) time.Duration {
+	delay := natsRetryDelay << (attempt - 1)
+	if delay <= 0 || delay > natsMaxRetryWait {
+		return natsMaxRetryWait
+	}
+	return delay
+}
+
+// Ping makes a round trip to the server, which fails while the connection is being re-established
+func (b *NATSBroker) Ping(ctx context.Context) error {
+	return b.conn.FlushWithContext(ctx)
+}
+
+// Close lets the consumers finish the messages they already received, then disconnects
+func (b *NATSBroker) Close() error {
+	b.mu.Lock()
+	consuming := b.consuming
+	b.consuming = nil
+	b.mu.Unlock()
+
+	for _, c := range consuming {
+		c.Drain()
+	}
+	// Without a connection the server can't confirm the drain, don't wait for it forever
+	timeout := time.After(natsSetupTimeout)
+	for _, c := range consuming {
+		select {
+		case <-c.Closed():
+		case <-timeout:
+		}
+	}
+	return b.conn.Drain()
+}
+
+func (b *NATSBroker) subject(eventType enums.EventType) string {
+	return b.prefix + "." + string(eventType)
+}
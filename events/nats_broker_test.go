@@ -0,0 +1,219 @@
+package events
+
+import (
+	"banter/constants/enums"
+	"banter/utils/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const testMaxDeliver = 3
+
+func TestMain(m *testing.M) {
+	natsRetryDelay = 10 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+// startNATSBroker runs an embedded NATS server with JetStream and connects a broker to it
+func startNATSBroker(t *testing.T) *NATSBroker {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("create NATS server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatalf("NATS server did not start")
+	}
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+
+	broker, err := NewNATSBroker(config.NatsConfig{
+		Url:           srv.ClientURL(),
+		SubjectPrefix: "test.events",
+		Stream:        "TEST_EVENTS",
+		MaxDeliver:    testMaxDeliver,
+	}, time.Hour)
+	if err != nil {
+		t.Fatalf("connect broker: %v", err)
+	}
+	t.Cleanup(func() { broker.Close() })
+	return broker
+}
+
+func testMessage() Message {
+	return Message{
+		ID:            uuid.New(),
+		Type:          enums.EventMessageCreated,
+		AggregateType: "message",
+		AggregateID:   uuid.New(),
+		CreatedAt:     time.Now(),
+		Payload:       json.RawMessage(`{"content":"hello"}`),
+	}
+}
+
+// eventually fails the test unless ok becomes true within a few seconds
+func eventually(t *testing.T, what string, ok func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !ok() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// settled reports whether the consumer has no message left to deliver or waiting for an ack
+func settled(t *testing.T, broker *NATSBroker, durable string) bool {
+	t.Helper()
+	consumer, err := broker.js.Consumer(context.Background(), broker.stream, durable)
+	if err != nil {
+		t.Fatalf("load consumer: %v", err)
+	}
+	info, err := consumer.Info(context.Background())
+	if err != nil {
+		t.Fatalf("consumer info: %v", err)
+	}
+	return info.NumPending == 0 && info.NumAckPending == 0 && info.Delivered.Stream > 0
+}
+
+func TestNATSBrokerAcksHandledEvents(t *testing.T) {
+	broker := startNATSBroker(t)
+
+	var handled atomic.Int32
+	var received atomic.Value
+	err := broker.Subscribe(enums.EventMessageCreated, "test", func(ctx context.Context, message Message) error {
+		received.Store(message)
+		handled.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	message := testMessage()
+	if err := broker.Publish(context.Background(), message); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	eventually(t, "the event to be acked", func() bool { return settled(t, broker, "test_message_created") })
+
+	if handled.Load() != 1 {
+		t.Fatalf("got %d deliveries, want 1", handled.Load())
+	}
+	if got := received.Load().(Message); got.ID != message.ID || string(got.Payload) != string(message.Payload) {
+		t.Fatalf("got %+v, want %+v", got, message)
+	}
+}
+
+func TestNATSBrokerDropsRepublishedEvents(t *testing.T) {
+	broker := startNATSBroker(t)
+
+	// The relay publishes again when it fails to record an event as published
+	message := testMessage()
+	for i := 0; i < 2; i++ {
+		if err := broker.Publish(context.Background(), message); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	stream, err := broker.js.Stream(context.Background(), broker.stream)
+	if err != nil {
+		t.Fatalf("load stream: %v", err)
+	}
+	info, err := stream.Info(context.Background())
+	if err != nil {
+		t.Fatalf("stream info: %v", err)
+	}
+	if info.State.Msgs != 1 {
+		t.Fatalf("got %d messages in the stream, want 1", info.State.Msgs)
+	}
+}
+
+func TestNATSBrokerRedeliversFailedEvents(t *testing.T) {
+	broker := startNATSBroker(t)
+
+	var attempts atomic.Int32
+	err := broker.Subscribe(enums.EventMessageCreated, "test", func(ctx context.Context, message Message) error {
+		if attempts.Add(1) == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	if err := broker.Publish(context.Background(), testMessage()); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	eventually(t, "the redelivered event to be acked", func() bool {
+		return attempts.Load() == 2 && settled(t, broker, "test_message_created")
+	})
+}
+
+func TestNATSBrokerTerminatesEventsAfterMaxDeliver(t *testing.T) {
+	broker := startNATSBroker(t)
+
+	var attempts atomic.Int32
+	err := broker.Subscribe(enums.EventMessageCreated, "test", func(ctx context.Context, message Message) error {
+		attempts.Add(1)
+		return errors.New("permanent failure")
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	if err := broker.Publish(context.Background(), testMessage()); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	eventually(t, "the event to be terminated", func() bool {
+		return attempts.Load() == testMaxDeliver && settled(t, broker, "test_message_created")
+	})
+
+	// Terminated events are not delivered again
+	time.Sleep(20 * natsRetryDelay)
+	if attempts.Load() != testMaxDeliver {
+		t.Fatalf("got %d attempts, want %d", attempts.Load(), testMaxDeliver)
+	}
+}
+
+func TestNATSBrokerTerminatesMalformedMessages(t *testing.T) {
+	broker := startNATSBroker(t)
+
+	var handled atomic.Int32
+	err := broker.Subscribe(enums.EventMessageCreated, "test", func(ctx context.Context, message Message) error {
+		handled.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	if _, err := broker.js.Publish(context.Background(), broker.subject(enums.EventMessageCreated), []byte("not json"),
+		jetstream.WithMsgID(uuid.NewString())); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	eventually(t, "the malformed message to be terminated", func() bool { return settled(t, broker, "test_message_created") })
+	if handled.Load() != 0 {
+		t.Fatalf("handler ran %d times for a malformed message", handled.Load())
+	}
+}
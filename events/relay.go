@@ -0,0 +1,144 @@
+package events
+
+import (
+	"banter/models"
+	"banter/repositories"
+	"banter/utils/config"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	publishTimeout = 10 * time.Second
+	maxRetryWait   = 5 * time.Minute
+	cleanupEvery   = time.Hour
+)
+
+// StartRelay publishes committed outbox events to the broker, oldest first. An event that fails to
+// publish is retried with exponential backoff until it succeeds, while later events are published in
+// the meantime, so consumers may receive events out of order. The relay stops once ctx is cancelled
+// and the event in flight has been published.
+func StartRelay(ctx context.Context, wg *sync.WaitGroup, broker Broker, store repositories.Store) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(relayInterval())
+		defer ticker.Stop()
+
+		lastCleanup := time.Time{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			relayDue(ctx, broker, store)
+
+			if time.Since(lastCleanup) > cleanupEvery {
+				cleanup(ctx, store)
+				lastCleanup = time.Now()
+			}
+		}
+	}()
+}
+
+func relayDue(ctx context.Context, broker Broker, store repositories.Store) {
+	outboxEvents, err := store.Events().ListDue(ctx, time.Now(), relayBatchSize())
+	if err != nil {
+		slog.Error("Failed to fetch due outbox events", "error", err)
+		return
+	}
+
+	for i := range outboxEvents {
+		if ctx.Err() != nil {
+			return
+		}
+		event := &outboxEvents[i]
+
+		// Lease the event so other replicas skip it while we publish
+		leased, err := store.Events().Lease(ctx, event, time.Now().Add(2*publishTimeout))
+		if err != nil {
+			slog.Error("Failed to lease outbox event", "event_id", event.ID, "error", err)
+			continue
+		}
+		if !leased {
+			continue
+		}
+
+		// A published event is recorded even when the relay is stopping
+		publish(context.WithoutCancel(ctx), broker, store, event)
+	}
+}
+
+func publish(ctx context.Context, broker Broker, store repositories.Store, event *models.OutboxEvent) {
+	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	event.Attempts++
+	err := broker.Publish(publishCtx, Message{
+		ID:            event.ID,
+		Type:          event.Type,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		CreatedAt:     event.CreatedAt,
+		Payload:       json.RawMessage(event.Payload),
+	})
+	if err == nil {
+		now := time.Now()
+		event.PublishedAt = &now
+		event.LastError = ""
+	} else {
+		// Retry with exponential backoff
+		wait := time.Second << min(event.Attempts-1, 20)
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+		event.NextAttemptAt = time.Now().Add(wait)
+		event.LastError = err.Error()
+		if len(event.LastError) > 1024 {
+			event.LastError = event.LastError[:1024]
+		}
+		slog.Warn("Failed to publish outbox event", "event_id", event.ID, "attempt", event.Attempts, "error", err)
+	}
+
+	if err := store.Events().Update(ctx, event); err != nil {
+		slog.Error("Failed to update outbox event", "event_id", event.ID, "error", err)
+	}
+}
+
+// cleanup removes published events and processed event records past the retention period
+func cleanup(ctx context.Context, store repositories.Store) {
+	before := time.Now().AddDate(0, 0, -retentionInDays())
+
+	if _, err := store.Events().DeletePublished(ctx, before); err != nil {
+		slog.Error("Failed to remove published outbox events", "error", err)
+	}
+	if _, err := store.Events().DeleteProcessed(ctx, before); err != nil {
+		slog.Error("Failed to remove processed event records", "error", err)
+	}
+}
+
+func relayInterval() time.Duration {
+	if config.Configs.Events.RelayIntervalInMs <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(config.Configs.Events.RelayIntervalInMs) * time.Millisecond
+}
+
+func relayBatchSize() int {
+	if config.Configs.Events.RelayBatchSize <= 0 {
+		return 100
+	}
+	return config.Configs.Events.RelayBatchSize
+}
+
+func retentionInDays() int {
+	if config.Configs.Events.RetentionInDays <= 0 {
+		return 7
+	}
+	return config.Configs.Events.RetentionInDays
+}
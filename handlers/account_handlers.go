@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"banter/constants/enums"
+	"banter/i18n"
+	"banter/models"
+	"banter/responses"
+	"banter/schemas"
+	"banter/utils/config"
+	"banter/workers"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ScheduleAccountDeletionHandler schedules the authenticated user's account for deletion
+// @Summary Delete My Account
+// @Description Schedules the account for deletion after the configured grace period. Messages in group chats are anonymised, everything else is erased.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body schemas.DeleteAccountSchema true "Password confirmation"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 401 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /account/deletion [post]
+// @Security AuthorizationToken
+func (h *Handler) ScheduleAccountDeletionHandler(c *gin.Context) {
+	var input schemas.DeleteAccountSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	user := currentUser(c)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		responses.Unauthorized(c, enums.CodeInvalidCredentials, "Authentication Error", "Password is incorrect")
+		return
+	}
+
+	scheduledAt := time.Now().AddDate(0, 0, config.Configs.Accounts.DeletionGracePeriodInDays)
+	user.DeletionScheduledAt = &scheduledAt
+	if err := h.store.Users().Update(c.Request.Context(), user); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditAccountDeletionRequest, "user", user.ID.String(), nil, gin.H{"deletion_scheduled_at": scheduledAt})
+
+	responses.Ok(c, gin.H{
+		"message":               "Account scheduled for deletion",
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	})
+}
+
+// CancelAccountDeletionHandler cancels a pending account deletion
+// @Summary Cancel Account Deletion
+// @Description Cancels a scheduled account deletion during the grace period
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /account/deletion [delete]
+// @Security AuthorizationToken
+func (h *Handler) CancelAccountDeletionHandler(c *gin.Context) {
+	user := currentUser(c)
+	if user.DeletionScheduledAt == nil {
+		responses.BadRequest(c, enums.CodeDeletionNotScheduled, "Invalid Request", "Account is not scheduled for deletion")
+		return
+	}
+
+	user.DeletionScheduledAt = nil
+	if err := h.store.Users().Update(c.Request.Context(), user); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditAccountDeletionCancel, "user", user.ID.String(), nil, nil)
+
+	responses.Ok(c, gin.H{"message": "Account deletion cancelled"})
+}
+
+// RequestDataExportHandler starts an export of the authenticated user's data
+// @Summary Request Data Export
+// @Description Starts an asynchronous export of the profile, conversations, messages and attachments as a ZIP archive
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 202 {object} responses.SuccessBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /account/exports [post]
+// @Security AuthorizationToken
+func (h *Handler) RequestDataExportHandler(c *gin.Context) {
+	user := currentUser(c)
+
+	export := models.DataExport{UserID: user.ID}
+	if err := h.store.DataExports().Create(c.Request.Context(), &export); err != nil {
+		respondError(c, "Failed to create export", err)
+		return
+	}
+
+	workers.EnqueueDataExport(export.ID)
+	h.recordAudit(c, nil, enums.AuditAccountExportRequest, "data_export", export.ID.String(), nil, nil)
+
+	responses.Accepted(c, dataExportDetails(&export))
+}
+
+// GetDataExportHandler fetches the status of a data export
+// @Summary Get Data Export
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Router /account/exports/{id} [get]
+// @Security AuthorizationToken
+func (h *Handler) GetDataExportHandler(c *gin.Context) {
+	export, ok := h.loadOwnDataExport(c)
+	if !ok {
+		return
+	}
+
+	responses.Ok(c, dataExportDetails(export))
+}
+
+// DownloadDataExportHandler downloads a completed data export
+// @Summary Download Data Export
+// @Tags Account
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Success 200 {file} file
+// @Failure 400 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Router /account/exports/{id}/download [get]
+// @Security AuthorizationToken
+func (h *Handler) DownloadDataExportHandler(c *gin.Context) {
+	export, ok := h.loadOwnDataExport(c)
+	if !ok {
+		return
+	}
+
+	if export.Status != enums.ExportCompleted {
+		responses.BadRequest(c, enums.CodeExportNotReady, "Export Not Ready", i18n.Translate(c.Request.Context(), "Export is {status}", "status", string(export.Status)))
+		return
+	}
+
+	c.FileAttachment(export.FilePath, fmt.Sprintf("banter-export-%s.zip", export.CreatedAt.Format("2006-01-02")))
+}
+
+// loadOwnDataExport fetches the export from the id path parameter if it belongs to the authenticated user
+func (h *Handler) loadOwnDataExport(c *gin.Context) (*models.DataExport, bool) {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Export ID", "Must be a valid UUID")
+		return nil, false
+	}
+
+	export, err := h.store.DataExports().GetByID(c.Request.Context(), exportID)
+	if err != nil || export.UserID != currentUser(c).ID {
+		responses.NotFound(c, enums.CodeExportNotFound, "Export Not Found", "No export found with the given ID")
+		return nil, false
+	}
+
+	return export, true
+}
+
+// dataExportDetails builds the public representation of a data export
+func dataExportDetails(export *models.DataExport) gin.H {
+	return gin.H{
+		"id":           export.ID,
+		"status":       export.Status,
+		"error":        export.Error,
+		"created_at":   export.CreatedAt,
+		"completed_at": export.CompletedAt,
+		"expires_at":   export.ExpiresAt,
+	}
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/repositories"
+	"banter/responses"
+	"banter/schemas"
+	"banter/workers"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListUsersHandler lists users for staff with filters and pagination
+// @Summary List Users
+// @Description Lists users filtered by status, staff flag and creation date range (DD-MM-YYYY)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param status query string false "User status (active, inactive, banned)"
+// @Param is_staff query bool false "Staff flag"
+// @Param created_from query string false "Created on or after (DD-MM-YYYY)"
+// @Param created_to query string false "Created on or before (DD-MM-YYYY)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/users [get]
+// @Security AuthorizationToken
+func (h *Handler) ListUsersHandler(c *gin.Context) {
+	var input schemas.AdminUserFilterSchema
+
+	if err := c.ShouldBindQuery(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	if input.Page < 1 {
+		input.Page = 1
+	}
+	if input.Limit < 1 {
+		input.Limit = 10
+	}
+
+	filter := models.UserFilter{
+		Status:      enums.UserStatus(input.Status),
+		IsStaff:     input.IsStaff,
+		CreatedFrom: input.CreatedFrom,
+	}
+	if !input.CreatedTo.IsZero() {
+		// Include the whole of the last day
+		filter.CreatedTo = input.CreatedTo.AddDate(0, 0, 1)
+	}
+
+	users, total, err := h.store.Users().Filter(c.Request.Context(), filter, input.Page, input.Limit)
+	if err != nil {
+		respondError(c, "Failed to fetch users", err)
+		return
+	}
+
+	results := make([]gin.H, 0, len(users))
+	for i := range users {
+		details := userDetails(&users[i])
+		details["status_reason"] = users[i].StatusReason
+		details["status_expires_at"] = users[i].StatusExpiresAt
+		details["password_reset_required"] = users[i].PasswordResetRequired
+		results = append(results, details)
+	}
+
+	nextPage, hasNextPage := nextPageOf(input.Page, input.Limit, total)
+
+	responses.Ok(c, gin.H{
+		"users":         results,
+		"total":         total,
+		"current_page":  input.Page,
+		"next_page":     nextPage,
+		"has_next_page": hasNextPage,
+	})
+}
+
+// BanUserHandler bans a user
+// @Summary Ban User
+// @Description Bans a user with a reason and an optional expiry
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body schemas.UserStatusChangeSchema true "Ban details"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/users/{id}/ban [post]
+// @Security AuthorizationToken
+func (h *Handler) BanUserHandler(c *gin.Context) {
+	h.changeUserStatus(c, enums.UserBanned, enums.AuditUserBanned)
+}
+
+// UnbanUserHandler lifts a ban or deactivation
+// @Summary Unban User
+// @Description Restores a banned or deactivated user to active with a reason
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body schemas.UserStatusChangeSchema true "Unban details"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/users/{id}/unban [post]
+// @Security AuthorizationToken
+func (h *Handler) UnbanUserHandler(c *gin.Context) {
+	h.changeUserStatus(c, enums.UserActive, enums.AuditUserUnbanned)
+}
+
+// DeactivateUserHandler deactivates a user
+// @Summary Deactivate User
+// @Description Deactivates a user with a reason and an optional expiry
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body schemas.UserStatusChangeSchema true "Deactivation details"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/users/{id}/deactivate [post]
+// @Security AuthorizationToken
+func (h *Handler) DeactivateUserHandler(c *gin.Context) {
+	h.changeUserStatus(c, enums.UserInactive, enums.AuditUserDeactivated)
+}
+
+// ForceLogoutHandler revokes every session of a user
+// @Summary Force Logout
+// @Description Invalidates all tokens previously issued to the user
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/users/{id}/logout [post]
+// @Security AuthorizationToken
+func (h *Handler) ForceLogoutHandler(c *gin.Context) {
+	user, ok := h.loadManagedUser(c)
+	if !ok {
+		return
+	}
+
+	user.RevokeSessions()
+	if err := h.store.Users().Update(c.Request.Context(), user); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditUserLoggedOut, "user", user.ID.String(), nil, nil)
+
+	responses.Ok(c, gin.H{"message": "User logged out successfully"})
+}
+
+// ForcePasswordResetHandler requires a user to change their password
+// @Summary Force Password Reset
+// @Description Revokes all sessions and requires the user to change their password on next login
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/users/{id}/password-reset [post]
+// @Security AuthorizationToken
+func (h *Handler) ForcePasswordResetHandler(c *gin.Context) {
+	user, ok := h.loadManagedUser(c)
+	if !ok {
+		return
+	}
+
+	user.PasswordResetRequired = true
+	user.RevokeSessions()
+	if err := h.store.Users().Update(c.Request.Context(), user); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditUserPasswordResetForce, "user", user.ID.String(), nil, nil)
+
+	responses.Ok(c, gin.H{"message": "Password reset required for user"})
+}
+
+// HardDeleteUserHandler permanently erases a user
+// @Summary Hard Delete User
+// @Description Permanently erases the user, their messages, attachments and memberships (GDPR erasure)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/users/{id} [delete]
+// @Security AuthorizationToken
+func (h *Handler) HardDeleteUserHandler(c *gin.Context) {
+	user, ok := h.loadManagedUser(c)
+	if !ok {
+		return
+	}
+
+	if err := workers.RemoveUserDataExports(c.Request.Context(), h.store, user.ID); err != nil {
+		respondError(c, "Failed to remove user exports", err)
+		return
+	}
+
+	if err := h.store.Users().HardDelete(c.Request.Context(), user.ID); err != nil {
+		respondError(c, "Failed to delete user", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditUserErased, "user", user.ID.String(), gin.H{"username": user.Username}, nil)
+
+	responses.Ok(c, gin.H{"message": "User erased successfully"})
+}
+
+// ListAuditLogsHandler searches the audit log
+// @Summary List Audit Logs
+// @Description Searches the audit log by actor, target, action and time range (RFC 3339), newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param actor_id query string false "Actor user ID"
+// @Param target_type query string false "Target type (user, conversation, data_export)"
+// @Param target_id query string false "Target ID"
+// @Param action query string false "Action, e.g. conversation.deleted"
+// @Param from query string false "Created at or after (RFC 3339)"
+// @Param to query string false "Created at or before (RFC 3339)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/audit-logs [get]
+// @Security AuthorizationToken
+func (h *Handler) ListAuditLogsHandler(c *gin.Context) {
+	var input schemas.AuditLogFilterSchema
+
+	if err := c.ShouldBindQuery(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	if input.Page < 1 {
+		input.Page = 1
+	}
+	if input.Limit < 1 {
+		input.Limit = 10
+	}
+
+	filter := models.AuditLogFilter{
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		Action:     enums.AuditAction(input.Action),
+		From:       input.From,
+		To:         input.To,
+	}
+	if input.ActorID != "" {
+		actorID := uuid.MustParse(input.ActorID) // validated by the schema
+		filter.ActorID = &actorID
+	}
+
+	logs, total, err := h.store.AuditLogs().Filter(c.Request.Context(), filter, input.Page, input.Limit)
+	if err != nil {
+		respondError(c, "Failed to fetch audit logs", err)
+		return
+	}
+
+	nextPage, hasNextPage := nextPageOf(input.Page, input.Limit, total)
+
+	responses.Ok(c, gin.H{
+		"audit_logs":    logs,
+		"total":         total,
+		"current_page":  input.Page,
+		"next_page":     nextPage,
+		"has_next_page": hasNextPage,
+	})
+}
+
+// changeUserStatus applies a status change with the reason and expiry from the request body
+func (h *Handler) changeUserStatus(c *gin.Context, status enums.UserStatus, action enums.AuditAction) {
+	var input schemas.UserStatusChangeSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	user, ok := h.loadManagedUser(c)
+	if !ok {
+		return
+	}
+
+	// Lifting a restriction never expires
+	expiresAt := input.ExpiresAt
+	if status == enums.UserActive {
+		expiresAt = nil
+	}
+
+	before := userStatusDetails(user)
+	user.SetStatus(status, input.Reason, expiresAt)
+	if err := h.store.Users().Update(c.Request.Context(), user); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+
+	h.recordAudit(c, nil, action, "user", user.ID.String(), before, userStatusDetails(user))
+
+	responses.Ok(c, userStatusDetails(user))
+}
+
+// userStatusDetails builds the representation of a user's status
+func userStatusDetails(user *models.User) gin.H {
+	return gin.H{
+		"id":                user.ID,
+		"status":            user.Status,
+		"status_reason":     user.StatusReason,
+		"status_expires_at": user.StatusExpiresAt,
+	}
+}
+
+// loadManagedUser fetches the user from the id path parameter and checks the caller may manage them
+func (h *Handler) loadManagedUser(c *gin.Context) (*models.User, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid User ID", "User ID must be a valid UUID")
+		return nil, false
+	}
+
+	user, err := h.store.Users().GetByID(c.Request.Context(), userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		responses.NotFound(c, enums.CodeUserNotFound, "User Not Found", "No user found with the given ID")
+		return nil, false
+	}
+	if err != nil {
+		respondError(c, "Failed to fetch user", err)
+		return nil, false
+	}
+
+	actor := currentUser(c)
+	if actor == nil || actor.ID == user.ID {
+		responses.Forbidden(c, enums.CodeForbidden, "Forbidden", "You cannot manage your own account")
+		return nil, false
+	}
+
+	// Only owners may manage other staff and owner accounts
+	if (user.IsStaff || user.IsOwner) && !actor.IsOwner {
+		responses.Forbidden(c, enums.CodeForbidden, "Forbidden", "Only owners can manage staff accounts")
+		return nil, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// recordAudit appends an entry to the audit log for the current request. Only the fields that differ
+// between before and after are stored. Failing to write the entry never fails the request.
+func (h *Handler) recordAudit(c *gin.Context, actorID *uuid.UUID, action enums.AuditAction, targetType string, targetID string, before, after gin.H) {
+	if actorID == nil {
+		if user := currentUser(c); user != nil {
+			actorID = &user.ID
+		}
+	}
+
+	entry := models.AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}
+
+	changedBefore, changedAfter := auditDiff(before, after)
+	if changedBefore != nil {
+		entry.Before, _ = json.Marshal(changedBefore)
+	}
+	if changedAfter != nil {
+		entry.After, _ = json.Marshal(changedAfter)
+	}
+
+	if err := h.store.AuditLogs().Create(c.Request.Context(), &entry); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to write audit log", "action", action, "error", err)
+	}
+}
+
+// auditDiff keeps only the keys whose values differ between before and after
+func auditDiff(before, after gin.H) (gin.H, gin.H) {
+	if before == nil || after == nil {
+		return before, after
+	}
+
+	changedBefore := gin.H{}
+	changedAfter := gin.H{}
+	for key, value := range before {
+		if !sameAuditValue(value, after[key]) {
+			changedBefore[key] = value
+			changedAfter[key] = after[key]
+		}
+	}
+	for key, value := range after {
+		if _, exists := before[key]; !exists {
+			changedBefore[key] = nil
+			changedAfter[key] = value
+		}
+	}
+
+	return changedBefore, changedAfter
+}
+
+// sameAuditValue compares two values by their JSON representation as that is how they are stored
+func sameAuditValue(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
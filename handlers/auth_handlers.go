@@ -3,17 +3,22 @@ package handlers
 import (
 	"banter/constants/enums"
 	"banter/models"
+	"banter/repositories"
 	"banter/responses"
 	"banter/schemas"
 	"banter/utils/config"
 	"banter/utils/jwt"
-	"time"
+	"errors"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// invalidCredentialsMessage doesn't say whether the user or the password was wrong, so logins can't
+// be used to find out which accounts exist
+const invalidCredentialsMessage = "Invalid username, email or password"
+
 // RegisterHandler handles customer registration
 // @Summary Register a new customer
 // @Description Creates a new user with the provided details
@@ -21,30 +26,30 @@ import (
 // @Accept json
 // @Produce json
 // @Param request body schemas.RegisterSchema true "User registration data"
-// @Success 201 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Success 201 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
 // @Router /auth/register [post]
-func RegisterHandler(c *gin.Context) {
+func (h *Handler) RegisterHandler(c *gin.Context) {
 	var input schemas.RegisterSchema
 
 	// Bind JSON request body to input struct
 	if err := c.ShouldBindJSON(&input); err != nil {
 		// Improved error handling to show validation failures
-		responses.BadRequest(c, "Invalid Input", err.Error())
+		responses.ValidationFailed(c, err)
 		return
 	}
 
 	// Proceed with password hashing
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		responses.InternalServerError(c, "Hashing Error", "Failed to hash password")
+		responses.InternalServerError(c, enums.CodeInternalError, "Hashing Error", "Failed to hash password")
 		return
 	}
 
 	dob, err := schemas.ParseDOB(input.DateOfBirth)
 	if err != nil {
-		responses.BadRequest(c, "Invalid Input", err.Error())
+		responses.BadRequest(c, enums.CodeInvalidInput, "Invalid Input", err.Error())
 		return
 	}
 
@@ -63,8 +68,8 @@ func RegisterHandler(c *gin.Context) {
 	}
 
 	// Save user to the database
-	if err := user.CreateUser(); err != nil {
-		responses.InternalServerError(c, err.Error(), "Failed to create user")
+	if err := h.store.Users().Create(c.Request.Context(), &user); err != nil {
+		respondFailure(c, "Registration Error", "Failed to create user", err)
 		return
 	}
 
@@ -84,60 +89,80 @@ func RegisterHandler(c *gin.Context) {
 // @Failure 401 {object} responses.FailureBody
 // @Failure 500 {object} responses.FailureBody
 // @Router /auth/login [post]
-func LoginHandler(c *gin.Context) {
+func (h *Handler) LoginHandler(c *gin.Context) {
 	var input schemas.LoginSchema
 
 	// Bind JSON request body to input struct
 	if err := c.ShouldBindJSON(&input); err != nil {
 		// Improved error handling to show validation failures
-		responses.BadRequest(c, "Invalid Input", err.Error())
+		responses.ValidationFailed(c, err)
 		return
 	}
 
 	if input.Email == "" && input.Username == "" {
-		responses.BadRequest(c, "Invalid Input", "Please provide username or email")
+		responses.BadRequest(c, enums.CodeInvalidInput, "Invalid Input", "Please provide username or email")
 		return
 	}
 
 	// Find user by email or username
-	var user models.User
-	err := models.GetUserByEmailOrUsername(input.Email, input.Username, &user)
+	user, err := h.store.Users().GetByEmailOrUsername(c.Request.Context(), input.Email, input.Username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		responses.Unauthorized(c, enums.CodeInvalidCredentials, "Authentication Error", invalidCredentialsMessage)
+		return
+	}
 	if err != nil {
-		responses.Unauthorized(c, "Authentication Error", err.Error())
+		respondError(c, "Authentication Error", err)
+		return
+	}
+
+	// Bots have no password and authenticate with API tokens
+	if user.IsBot() {
+		responses.Unauthorized(c, enums.CodeInvalidCredentials, "Authentication Error", "Bot accounts must use an API token")
 		return
 	}
 
-	if user.Status == enums.UserBanned {
-		responses.Forbidden(c, "Account Banned", "User account is banned")
+	status := user.EffectiveStatus()
+	if status == enums.UserBanned {
+		responses.Forbidden(c, enums.CodeAccountBanned, "Account Banned", "User account is banned")
 		return
 	}
 
-	if user.Status == enums.UserInactive {
-		responses.Unauthorized(c, "Account Inactive", "User account is banned")
+	if status == enums.UserInactive {
+		responses.Unauthorized(c, enums.CodeAccountInactive, "Account Inactive", "User account is inactive")
 		return
 	}
 
+	// Lift a ban or deactivation whose expiry has passed
+	if status != user.Status {
+		user.SetStatus(status, "", nil)
+	}
+
 	// Compare provided password with stored hash
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		responses.Unauthorized(c, "Authentication Error", err.Error())
+		h.recordAudit(c, &user.ID, enums.AuditUserLoginFailed, "user", user.ID.String(), nil, nil)
+		responses.Unauthorized(c, enums.CodeInvalidCredentials, "Authentication Error", invalidCredentialsMessage)
 		return
 	}
 
 	// Generate JWT token
-	tokenString, err := jwt.GenerateToken(user.ID.String(), config.Configs.Auth.TokenValidityInHrs)
+	tokenString, err := jwt.GenerateToken(user.ID.String(), user.SessionVersion, config.Configs.Auth.TokenValidityInHrs)
 	if err != nil {
-		responses.InternalServerError(c, "Token Generation Error", "Failed to generate token")
+		responses.InternalServerError(c, enums.CodeInternalError, "Token Generation Error", "Failed to generate token")
 		return
 	}
 
-	lastSeen := time.Now()
-	// update the last seen
-	user.LastSeen = &lastSeen
-	err = user.UpdateUser()
+	// update the last seen, without saving the whole user so a revocation made meanwhile isn't undone
+	err = h.store.Users().TouchLastSeen(c.Request.Context(), user.ID)
 	if err != nil {
-		responses.InternalServerError(c, "Data Updation Error", "Error updating data")
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
 		return
 	}
+
+	h.recordAudit(c, &user.ID, enums.AuditUserLogin, "user", user.ID.String(), nil, nil)
+
 	// Success response with token
-	responses.Ok(c, gin.H{"token": tokenString})
+	responses.Ok(c, gin.H{
+		"token":                   tokenString,
+		"password_reset_required": user.PasswordResetRequired,
+	})
 }
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/responses"
+	"banter/schemas"
+	"banter/utils/apitoken"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateBotHandler creates a bot account
+// @Summary Create Bot
+// @Description Creates a bot user owned by the caller. Bots post into conversations they are members of using API tokens and receive the slash commands they register on their webhook. The webhook secret is only returned here.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param bot body schemas.CreateBotSchema true "Bot data"
+// @Success 201 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/bots [post]
+// @Security AuthorizationToken
+func (h *Handler) CreateBotHandler(c *gin.Context) {
+	var input schemas.CreateBotSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		respondError(c, "Failed to generate secret", err)
+		return
+	}
+
+	bot := models.Bot{
+		OwnerID:            currentUser(c).ID,
+		Description:        input.Description,
+		WebhookURL:         input.WebhookURL,
+		WebhookSecret:      secret,
+		RateLimitPerMinute: input.RateLimitPerMinute,
+		User: models.User{
+			ID:        uuid.New(),
+			Username:  input.Username,
+			Email:     input.Username + "@bots.invalid",
+			FirstName: input.DisplayName,
+			Status:    enums.UserActive,
+			Type:      enums.UserTypeBot,
+		},
+	}
+	bot.SetCommands(input.Commands)
+
+	if err := h.store.Bots().Create(c.Request.Context(), &bot); err != nil {
+		respondError(c, "Failed to create bot", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditBotCreated, "user", bot.UserID.String(), nil, botDetails(&bot))
+
+	details := botDetails(&bot)
+	details["webhook_secret"] = bot.WebhookSecret
+	responses.Created(c, gin.H{"bot": details})
+}
+
+// GetBotsHandler lists bot accounts
+// @Summary Get Bots
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.SuccessBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/bots [get]
+// @Security AuthorizationToken
+func (h *Handler) GetBotsHandler(c *gin.Context) {
+	bots, err := h.store.Bots().List(c.Request.Context())
+	if err != nil {
+		respondError(c, "Failed to fetch bots", err)
+		return
+	}
+
+	results := make([]gin.H, 0, len(bots))
+	for i := range bots {
+		results = append(results, botDetails(&bots[i]))
+	}
+
+	responses.Ok(c, gin.H{"bots": results})
+}
+
+// UpdateBotHandler updates a bot account
+// @Summary Update Bot
+// @Description Updates the display name, description, webhook, commands or rate limit of a bot. Only the provided fields are updated, an empty webhook_url removes the webhook.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Bot user ID"
+// @Param bot body schemas.UpdateBotSchema true "Bot update data"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/bots/{id} [patch]
+// @Security AuthorizationToken
+func (h *Handler) UpdateBotHandler(c *gin.Context) {
+	var input schemas.UpdateBotSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	if input.WebhookURL != nil && *input.WebhookURL != "" && !isHTTPURL(*input.WebhookURL) {
+		responses.BadRequest(c, enums.CodeInvalidInput, "Invalid Input", "webhook_url must be an http or https URL")
+		return
+	}
+
+	bot, ok := h.loadBot(c)
+	if !ok {
+		return
+	}
+	before := botDetails(bot)
+
+	// Update only the provided fields
+	if input.DisplayName != nil {
+		bot.User.FirstName = *input.DisplayName
+	}
+	if input.Description != nil {
+		bot.Description = *input.Description
+	}
+	if input.WebhookURL != nil {
+		bot.WebhookURL = *input.WebhookURL
+	}
+	if input.Commands != nil {
+		bot.SetCommands(input.Commands)
+	}
+	if input.RateLimitPerMinute != nil {
+		bot.RateLimitPerMinute = *input.RateLimitPerMinute
+	}
+
+	if err := h.store.Bots().Update(c.Request.Context(), bot); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+	if input.DisplayName != nil {
+		if err := h.store.Users().Update(c.Request.Context(), &bot.User); err != nil {
+			respondFailure(c, "Data Updation Error", "Error updating data", err)
+			return
+		}
+	}
+
+	h.recordAudit(c, nil, enums.AuditBotUpdated, "user", bot.UserID.String(), before, botDetails(bot))
+
+	responses.Ok(c, gin.H{"bot": botDetails(bot)})
+}
+
+// DeleteBotHandler deletes a bot account
+// @Summary Delete Bot
+// @Description Deletes the bot account and revokes all of its API tokens
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Bot user ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/bots/{id} [delete]
+// @Security AuthorizationToken
+func (h *Handler) DeleteBotHandler(c *gin.Context) {
+	bot, ok := h.loadBot(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Bots().Delete(c.Request.Context(), bot); err != nil {
+		respondError(c, "Failed to delete bot", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditBotDeleted, "user", bot.UserID.String(), gin.H{"username": bot.User.Username}, nil)
+
+	responses.Ok(c, gin.H{"message": "Bot deleted successfully"})
+}
+
+// CreateApiTokenHandler issues an API token for a bot
+// @Summary Create Bot API Token
+// @Description Issues a long-lived API token for the bot, sent as 'Bearer <token>'. The read scope allows GET requests, the write scope every other request. The token is only returned here.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Bot user ID"
+// @Param token body schemas.CreateApiTokenSchema true "Token data"
+// @Success 201 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/bots/{id}/tokens [post]
+// @Security AuthorizationToken
+func (h *Handler) CreateApiTokenHandler(c *gin.Context) {
+	var input schemas.CreateApiTokenSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	bot, ok := h.loadBot(c)
+	if !ok {
+		return
+	}
+
+	tokenString, hash, prefix, err := apitoken.Generate()
+	if err != nil {
+		responses.InternalServerError(c, enums.CodeInternalError, "Token Generation Error", "Failed to generate token")
+		return
+	}
+
+	token := models.ApiToken{
+		UserID:    bot.UserID,
+		Name:      input.Name,
+		TokenHash: hash,
+		Prefix:    prefix,
+	}
+	scopes := make([]enums.TokenScope, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scopes = append(scopes, enums.TokenScope(scope))
+	}
+	token.SetScopes(scopes)
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := h.store.ApiTokens().Create(c.Request.Context(), &token); err != nil {
+		respondError(c, "Failed to create token", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditApiTokenCreated, "api_token", token.ID.String(), nil, gin.H{"bot_id": bot.UserID, "scopes": token.ScopeList()})
+
+	details := apiTokenDetails(&token)
+	details["token"] = tokenString
+	responses.Created(c, gin.H{"token": details})
+}
+
+// GetApiTokensHandler lists the API tokens of a bot
+// @Summary Get Bot API Tokens
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Bot user ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/bots/{id}/tokens [get]
+// @Security AuthorizationToken
+func (h *Handler) GetApiTokensHandler(c *gin.Context) {
+	bot, ok := h.loadBot(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.store.ApiTokens().ListByUser(c.Request.Context(), bot.UserID)
+	if err != nil {
+		respondError(c, "Failed to fetch tokens", err)
+		return
+	}
+
+	results := make([]gin.H, 0, len(tokens))
+	for i := range tokens {
+		results = append(results, apiTokenDetails(&tokens[i]))
+	}
+
+	responses.Ok(c, gin.H{"tokens": results})
+}
+
+// RevokeApiTokenHandler revokes an API token of a bot
+// @Summary Revoke Bot API Token
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Bot user ID"
+// @Param token_id path string true "Token ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/bots/{id}/tokens/{token_id} [delete]
+// @Security AuthorizationToken
+func (h *Handler) RevokeApiTokenHandler(c *gin.Context) {
+	tokenID, err := uuid.Parse(c.Param("token_id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Token ID", "Must be a valid UUID")
+		return
+	}
+
+	bot, ok := h.loadBot(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.store.ApiTokens().Revoke(c.Request.Context(), tokenID, bot.UserID)
+	if err != nil {
+		respondError(c, "Failed to revoke token", err)
+		return
+	}
+	if !revoked {
+		responses.NotFound(c, enums.CodeTokenNotFound, "Token Not Found", "No active token found with the given ID")
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditApiTokenRevoked, "api_token", tokenID.String(), gin.H{"bot_id": bot.UserID}, nil)
+
+	responses.Ok(c, gin.H{"message": "Token revoked successfully"})
+}
+
+// loadBot fetches the bot from the id path parameter
+func (h *Handler) loadBot(c *gin.Context) (*models.Bot, bool) {
+	botID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Bot ID", "Must be a valid UUID")
+		return nil, false
+	}
+
+	bot, err := h.store.Bots().GetByUserID(c.Request.Context(), botID)
+	if err != nil {
+		responses.NotFound(c, enums.CodeBotNotFound, "Bot Not Found", "No bot found with the given ID")
+		return nil, false
+	}
+
+	return bot, true
+}
+
+// botDetails builds the representation of a bot, leaving out the webhook secret
+func botDetails(bot *models.Bot) gin.H {
+	return gin.H{
+		"id":                    bot.UserID,
+		"username":              bot.User.Username,
+		"display_name":          bot.User.FirstName,
+		"description":           bot.Description,
+		"owner_id":              bot.OwnerID,
+		"webhook_url":           bot.WebhookURL,
+		"commands":              bot.CommandList(),
+		"rate_limit_per_minute": bot.RateLimitPerMinute,
+		"status":                bot.User.Status,
+		"created_at":            bot.CreatedAt,
+		"updated_at":            bot.UpdatedAt,
+	}
+}
+
+// apiTokenDetails builds the representation of an API token, leaving out the token itself
+func apiTokenDetails(token *models.ApiToken) gin.H {
+	return gin.H{
+		"id":           token.ID,
+		"name":         token.Name,
+		"prefix":       token.Prefix,
+		"scopes":       token.ScopeList(),
+		"expires_at":   token.ExpiresAt,
+		"last_used_at": token.LastUsedAt,
+		"revoked_at":   token.RevokedAt,
+		"created_at":   token.CreatedAt,
+	}
+}
+
+func isHTTPURL(value string) bool {
+	parsed, err := url.ParseRequestURI(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"banter/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser returns the authenticated user set by JWTMiddleware
+func currentUser(c *gin.Context) *models.User {
+	value, exists := c.Get("user")
+	if !exists {
+		return nil
+	}
+	user, _ := value.(*models.User)
+	return user
+}
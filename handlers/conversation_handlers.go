@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"banter/constants/enums"
+	"banter/i18n"
 	"banter/models"
+	"banter/notifications"
+	"banter/repositories"
 	"banter/responses"
 	"banter/schemas"
-	"net/http"
+	"context"
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -20,91 +25,104 @@ import (
 // @Param conversation body schemas.StartConversationSchema true "Conversation Data"
 // @Success 201 {object} responses.SuccessBody
 // @Failure 400 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
 // @Failure 500 {object} responses.FailureBody
 // @Router /conversation [post]
 // @Security AuthorizationToken
-func StartConversationHandler(c *gin.Context) {
+func (h *Handler) StartConversationHandler(c *gin.Context) {
 	var input schemas.StartConversationSchema
 
 	// Parse request body
 	if err := c.ShouldBindJSON(&input); err != nil {
-		responses.BadRequest(c, "Invalid Input", err.Error())
+		responses.ValidationFailed(c, err)
 		return
 	}
 
-	// Ensure there are at least 3 members in a group chat
-	if input.IsGroup && len(input.Members) < 3 {
-		responses.BadRequest(c, "Invalid Members", "Group chats must have at least 3 members")
-		return
-	}
-
-	// Generate conversation ID
-	conversationID := uuid.New()
-
-	// Create new conversation
-	conversation := models.Conversation{
-		ID:      conversationID,
-		Name:    input.Name,
-		IsGroup: input.IsGroup,
-	}
-
-	// Save conversation to DB
-	if err := conversation.CreateConversation(); err != nil {
-		responses.InternalServerError(c, "Failed to create conversation", err.Error())
+	conversation, err := h.conversations.StartConversation(c.Request.Context(), currentUser(c), input.Name, input.IsGroup, input.Members)
+	if err != nil {
+		respondError(c, "Failed to create conversation", err)
 		return
 	}
 
-	// Add members to conversation
-	if err := models.AddMembers(conversationID, input.Members); err != nil {
-		responses.InternalServerError(c, "Failed to add members", err.Error())
-		return
-	}
+	starterID, text := currentUser(c).ID, conversationStartedText(conversation, currentUser(c))
+	h.runInBackground(c, func(ctx context.Context) {
+		notifications.NotifyConversation(ctx, conversation.ID, starterID, i18n.NewMessage("New conversation"), text)
+	})
 
 	// Success response
-	c.JSON(http.StatusCreated, gin.H{
+	responses.Created(c, gin.H{
 		"message":       "Conversation created successfully",
 		"conversation":  conversation,
 		"members_count": len(input.Members),
 	})
 }
 
-// GetConversationsHandler fetches all conversations for a user with pagination
+// GetConversationsHandler fetches the conversations of a user with cursor pagination
 // @Summary Get User Conversations
+// @Description Pinned conversations come first, then the most recently active. Pass next_cursor from a page as cursor to fetch the following page. Only staff may list another user's conversations.
 // @Tags Conversation
 // @Accept json
 // @Produce json
 // @Param user_id path string true "User ID"
-// @Param page query int false "Page number (default: 1)"
-// @Param limit query int false "Items per page (default: 10)"
-// @Success 200 {array} models.ConversationWithMembers
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Param filter query string false "active (default), archived or all"
+// @Success 200 {object} responses.SuccessBody{data=models.PaginatedConversations}
 // @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
 // @Failure 500 {object} responses.FailureBody
 // @Router /conversations/member/{user_id} [get]
 // @Security AuthorizationToken
-func GetConversationsHandler(c *gin.Context) {
+func (h *Handler) GetConversationsHandler(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("user_id"))
 	if err != nil {
-		responses.BadRequest(c, "Invalid User ID", "Must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid User ID", "Must be a valid UUID")
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-
-	if page < 1 {
-		page = 1
+	// The list carries each member's pin, archive and mute settings, only staff may see another's
+	caller := currentUser(c)
+	if caller == nil || (caller.ID != userID && !caller.IsStaff && !caller.IsOwner) {
+		responses.Forbidden(c, enums.CodeForbidden, "Forbidden", "You can only list your own conversations")
+		return
 	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	if limit < 1 {
 		limit = 10
 	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	var cursor *models.ConversationCursor
+	if value := c.Query("cursor"); value != "" {
+		cursor, err = models.DecodeConversationCursor(value)
+		if err != nil {
+			responses.BadRequest(c, enums.CodeInvalidCursor, "Invalid Cursor", "Cursor must be the next_cursor of a previous page")
+			return
+		}
+	}
 
-	conversations, err := models.GetUserConversations(userID, page, limit)
+	filter := enums.ConversationFilter(c.DefaultQuery("filter", string(enums.ConversationsActive)))
+	switch filter {
+	case enums.ConversationsActive, enums.ConversationsArchived, enums.ConversationsAll:
+	default:
+		responses.BadRequest(c, enums.CodeInvalidFilter, "Invalid Filter", "Filter must be one of active, archived or all")
+		return
+	}
+
+	conversations, err := h.store.Conversations().ListForUser(c.Request.Context(), userID, filter, cursor, limit)
 	if err != nil {
-		responses.InternalServerError(c, "Failed to fetch conversations", err.Error())
+		respondError(c, "Failed to fetch conversations", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, conversations)
+	responses.Ok(c, gin.H{
+		"conversations": conversations.Conversations,
+		"next_cursor":   conversations.NextCursor,
+		"has_more":      conversations.HasMore,
+	})
 }
 
 // GetConversationHandler fetches conversation details
@@ -113,26 +131,31 @@ func GetConversationsHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Conversation ID"
-// @Success 200 {object} models.Conversation
+// @Success 200 {object} responses.SuccessBody{data=object{conversation=models.ConversationWithMembers}}
 // @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
 // @Failure 404 {object} responses.FailureBody
 // @Failure 500 {object} responses.FailureBody
 // @Router /conversation/{id} [get]
 // @Security AuthorizationToken
-func GetConversationHandler(c *gin.Context) {
+func (h *Handler) GetConversationHandler(c *gin.Context) {
 	conversationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		responses.BadRequest(c, "Invalid Conversation ID", "Must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Conversation ID", "Must be a valid UUID")
 		return
 	}
 
-	conversation, err := models.GetConversationByID(conversationID)
+	conversation, err := h.conversations.GetConversation(c.Request.Context(), currentUser(c), conversationID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		responses.NotFound(c, enums.CodeConversationNotFound, "Conversation Not Found", "No conversation found with the given ID")
+		return
+	}
 	if err != nil {
-		responses.NotFound(c, "Conversation Not Found", "No conversation found with the given ID")
+		respondError(c, "Failed to fetch conversation", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, conversation)
+	responses.Ok(c, gin.H{"conversation": conversation})
 }
 
 // AddMemberHandler adds a user to a conversation
@@ -144,28 +167,39 @@ func GetConversationHandler(c *gin.Context) {
 // @Param user_id path string true "User ID"
 // @Success 200 {object} responses.SuccessBody
 // @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 409 {object} responses.FailureBody
 // @Failure 500 {object} responses.FailureBody
 // @Router /conversation/{id}/member/{user_id} [post]
 // @Security AuthorizationToken
-func AddMemberHandler(c *gin.Context) {
+func (h *Handler) AddMemberHandler(c *gin.Context) {
 	conversationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		responses.BadRequest(c, "Invalid Conversation ID", "Must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Conversation ID", "Must be a valid UUID")
 		return
 	}
 
 	userID, err := uuid.Parse(c.Param("user_id"))
 	if err != nil {
-		responses.BadRequest(c, "Invalid User ID", "Must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid User ID", "Must be a valid UUID")
 		return
 	}
 
-	if err := models.AddMembers(conversationID, []uuid.UUID{userID}); err != nil {
-		responses.InternalServerError(c, "Failed to add member", err.Error())
+	if err := h.conversations.AddMember(c.Request.Context(), currentUser(c), conversationID, userID); err != nil {
+		respondError(c, "Failed to add member", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Member added successfully"})
+	h.recordAudit(c, nil, enums.AuditMemberAdded, "conversation", conversationID.String(), nil, gin.H{"member_id": userID})
+
+	text := i18n.NewMessage("{username} added you to a conversation", "username", currentUser(c).Username)
+	h.runInBackground(c, func(ctx context.Context) {
+		notifications.NotifyUsers(ctx, []uuid.UUID{userID}, i18n.NewMessage("Added to conversation"), text,
+			map[string]string{"conversation_id": conversationID.String()})
+	})
+
+	responses.Ok(c, gin.H{"message": "Member added successfully"})
 }
 
 // RemoveMemberHandler removes a user from a conversation
@@ -177,27 +211,32 @@ func AddMemberHandler(c *gin.Context) {
 // @Param user_id path string true "User ID"
 // @Success 200 {object} responses.SuccessBody
 // @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 409 {object} responses.FailureBody
 // @Failure 500 {object} responses.FailureBody
 // @Router /conversation/{id}/member/{user_id} [delete]
 // @Security AuthorizationToken
-func RemoveMemberHandler(c *gin.Context) {
+func (h *Handler) RemoveMemberHandler(c *gin.Context) {
 	conversationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		responses.BadRequest(c, "Invalid Conversation ID", "Must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Conversation ID", "Must be a valid UUID")
 		return
 	}
 	userID, err := uuid.Parse(c.Param("user_id"))
 	if err != nil {
-		responses.BadRequest(c, "Invalid User ID", "Must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid User ID", "Must be a valid UUID")
 		return
 	}
 
-	if err := models.RemoveMember(conversationID, userID); err != nil {
-		responses.InternalServerError(c, "Failed to remove member", err.Error())
+	if err := h.conversations.RemoveMember(c.Request.Context(), currentUser(c), conversationID, userID); err != nil {
+		respondError(c, "Failed to remove member", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+	h.recordAudit(c, nil, enums.AuditMemberRemoved, "conversation", conversationID.String(), gin.H{"member_id": userID}, nil)
+
+	responses.Ok(c, gin.H{"message": "Member removed successfully"})
 }
 
 // DeleteConversationHandler deletes a conversation
@@ -208,20 +247,102 @@ func RemoveMemberHandler(c *gin.Context) {
 // @Param id path string true "Conversation ID"
 // @Success 200 {object} responses.SuccessBody
 // @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
 // @Failure 500 {object} responses.FailureBody
 // @Router /conversation/{id} [delete]
 // @Security AuthorizationToken
-func DeleteConversationHandler(c *gin.Context) {
+func (h *Handler) DeleteConversationHandler(c *gin.Context) {
+	conversationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Conversation ID", "Must be a valid UUID")
+		return
+	}
+
+	if err := h.conversations.DeleteConversation(c.Request.Context(), currentUser(c), conversationID); err != nil {
+		respondError(c, "Failed to delete conversation", err)
+		return
+	}
+
+	h.recordAudit(c, nil, enums.AuditConversationDeleted, "conversation", conversationID.String(), nil, nil)
+
+	responses.Ok(c, gin.H{"message": "Conversation deleted successfully"})
+}
+
+// UpdateConversationSettingsHandler updates the authenticated user's settings for a conversation
+// @Summary Update Conversation Settings
+// @Description Pins, archives or mutes a conversation for the authenticated user. Only the provided fields are updated.
+// @Tags Conversation
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param settings body schemas.ConversationSettingsSchema true "Conversation settings"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /conversation/{id}/settings [patch]
+// @Security AuthorizationToken
+func (h *Handler) UpdateConversationSettingsHandler(c *gin.Context) {
 	conversationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		responses.BadRequest(c, "Invalid Conversation ID", "Must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Conversation ID", "Must be a valid UUID")
+		return
+	}
+
+	var input schemas.ConversationSettingsSchema
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
 		return
 	}
 
-	if err1, err2 := models.DeleteConversation(conversationID); err1 != nil && err2 != nil {
-		responses.InternalServerError(c, "Failed to delete conversation", err1.Error()+err2.Error())
+	if input.Unmute && input.MutedUntil != nil {
+		responses.BadRequest(c, enums.CodeInvalidInput, "Invalid Input", "Provide either muted_until or unmute, not both")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Conversation deleted successfully"})
+	membership, err := h.store.Members().Get(c.Request.Context(), conversationID, currentUser(c).ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		responses.NotFound(c, enums.CodeConversationNotFound, "Conversation Not Found", "You are not a member of this conversation")
+		return
+	}
+	if err != nil {
+		respondError(c, "Failed to fetch conversation settings", err)
+		return
+	}
+
+	// Update only the provided fields
+	if input.Pinned != nil {
+		membership.IsPinned = *input.Pinned
+	}
+	if input.Archived != nil {
+		membership.IsArchived = *input.Archived
+	}
+	if input.MutedUntil != nil {
+		membership.MutedUntil = input.MutedUntil
+	}
+	if input.Unmute {
+		membership.MutedUntil = nil
+	}
+
+	if err := h.store.Members().Update(c.Request.Context(), membership); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+
+	responses.Ok(c, gin.H{
+		"settings": models.ConversationSettings{
+			IsPinned:   membership.IsPinned,
+			IsArchived: membership.IsArchived,
+			MutedUntil: membership.MutedUntil,
+		},
+	})
+}
+
+// conversationStartedText describes a new conversation in notifications
+func conversationStartedText(conversation *models.Conversation, creator *models.User) i18n.Message {
+	if conversation.IsGroup && conversation.Name != "" {
+		return i18n.NewMessage("{username} added you to {conversation}", "username", creator.Username, "conversation", conversation.Name)
+	}
+	return i18n.NewMessage("{username} started a conversation with you", "username", creator.Username)
 }
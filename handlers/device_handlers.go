@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/responses"
+	"banter/schemas"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RegisterDeviceHandler registers a device for push notifications
+// @Summary Register Device
+// @Description Registers a device token of the authenticated user for push notifications. Registering a known token updates it.
+// @Tags Device
+// @Accept json
+// @Produce json
+// @Param device body schemas.RegisterDeviceSchema true "Device data"
+// @Success 201 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /devices [post]
+// @Security AuthorizationToken
+func (h *Handler) RegisterDeviceHandler(c *gin.Context) {
+	var input schemas.RegisterDeviceSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	device := models.Device{
+		UserID:     currentUser(c).ID,
+		Platform:   enums.DevicePlatform(input.Platform),
+		Token:      input.Token,
+		AppVersion: input.AppVersion,
+	}
+
+	if err := h.store.Devices().Register(c.Request.Context(), &device); err != nil {
+		respondError(c, "Failed to register device", err)
+		return
+	}
+
+	responses.Created(c, gin.H{"device": device})
+}
+
+// GetDevicesHandler lists the devices of the authenticated user
+// @Summary Get Devices
+// @Tags Device
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.SuccessBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /devices [get]
+// @Security AuthorizationToken
+func (h *Handler) GetDevicesHandler(c *gin.Context) {
+	devices, err := h.store.Devices().ListByUsers(c.Request.Context(), []uuid.UUID{currentUser(c).ID})
+	if err != nil {
+		respondError(c, "Failed to fetch devices", err)
+		return
+	}
+
+	responses.Ok(c, gin.H{"devices": devices})
+}
+
+// DeleteDeviceHandler unregisters a device
+// @Summary Delete Device
+// @Tags Device
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /devices/{id} [delete]
+// @Security AuthorizationToken
+func (h *Handler) DeleteDeviceHandler(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Device ID", "Must be a valid UUID")
+		return
+	}
+
+	deleted, err := h.store.Devices().DeleteForUser(c.Request.Context(), deviceID, currentUser(c).ID)
+	if err != nil {
+		respondError(c, "Failed to delete device", err)
+		return
+	}
+	if !deleted {
+		responses.NotFound(c, enums.CodeDeviceNotFound, "Device Not Found", "No device found with the given ID")
+		return
+	}
+
+	responses.Ok(c, gin.H{"message": "Device deleted successfully"})
+}
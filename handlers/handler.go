@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"banter/repositories"
+	"banter/services"
+	"context"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves the API. Its store is injected so the handlers can run against the database or
+// the in-memory store.
+type Handler struct {
+	store         repositories.Store
+	conversations *services.ConversationService
+	messages      *services.MessageService
+	background    sync.WaitGroup
+}
+
+// NewHandler creates the handlers using the given store
+func NewHandler(store repositories.Store) *Handler {
+	return &Handler{
+		store:         store,
+		conversations: services.NewConversationService(store),
+		messages:      services.NewMessageService(store),
+	}
+}
+
+// backgroundContext returns a context for work that carries on after the response is sent. It keeps
+// the request's trace and log fields but isn't cancelled when the request ends.
+func backgroundContext(c *gin.Context) context.Context {
+	return context.WithoutCancel(c.Request.Context())
+}
+
+// runInBackground runs fn with a background context of the request, without holding up the response.
+// fn must not use c, it may already serve another request by the time fn runs.
+func (h *Handler) runInBackground(c *gin.Context, fn func(ctx context.Context)) {
+	ctx := backgroundContext(c)
+	h.background.Add(1)
+	go func() {
+		defer h.background.Done()
+		fn(ctx)
+	}()
+}
+
+// Wait waits for the work requests left running in the background, such as notifying the members of
+// a conversation, returning early with the context's error if it isn't done in time. Call it once the
+// HTTP server no longer accepts requests.
+func (h *Handler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Store returns the store the handlers use
+func (h *Handler) Store() repositories.Store {
+	return h.store
+}
@@ -0,0 +1,445 @@
+package handlers_test
+
+import (
+	"banter/constants/enums"
+	"banter/handlers"
+	"banter/models"
+	"banter/repositories"
+	"banter/routes"
+	"banter/utils/config"
+	"banter/utils/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const testPassword = "Passw0rd!23"
+
+func TestMain(m *testing.M) {
+	logger.SetupLogger()
+	gin.SetMode(gin.TestMode)
+	config.Configs.Jwt.Secret = "test-secret"
+	config.Configs.Auth.TokenValidityInHrs = 1
+	config.Configs.Security = config.Defaults().Security
+	os.Exit(m.Run())
+}
+
+// testServer serves the API from an in-memory store
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+	store  *repositories.MemoryStore
+}
+
+func newTestServer(t *testing.T) *testServer {
+	store := repositories.NewMemoryStore()
+	router := gin.New()
+	handler := handlers.NewHandler(store)
+	routes.Register(router, handler)
+	// Notifications and bot commands run after the response, let them finish before the next test
+	t.Cleanup(func() { handler.Wait(context.Background()) })
+	return &testServer{t: t, router: router, store: store}
+}
+
+// request sends a JSON request and returns the recorded response along with its decoded data
+func (s *testServer) request(method, path, token string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			s.t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if recorder.Body.Len() > 0 {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			s.t.Fatalf("%s %s: decode response %q: %v", method, path, recorder.Body.String(), err)
+		}
+	}
+	return recorder, response.Data
+}
+
+// expect sends a request and fails the test unless it is answered with the wanted status
+func (s *testServer) expect(want int, method, path, token string, body interface{}) map[string]interface{} {
+	s.t.Helper()
+	recorder, data := s.request(method, path, token, body)
+	if recorder.Code != want {
+		s.t.Fatalf("%s %s: got status %d, want %d: %s", method, path, recorder.Code, want, recorder.Body.String())
+	}
+	return data
+}
+
+// signUp registers a user and logs them in, returning the user and their token
+func (s *testServer) signUp(username string) (*models.User, string) {
+	s.t.Helper()
+	s.expect(http.StatusCreated, http.MethodPost, "/auth/register", "", map[string]string{
+		"username":      username,
+		"email":         username + "@example.com",
+		"password":      testPassword,
+		"first_name":    "Test",
+		"last_name":     "User",
+		"date_of_birth": "01-01-1990",
+		"gender":        "other",
+		"mobile_number": "1234567890",
+	})
+	data := s.expect(http.StatusOK, http.MethodPost, "/auth/login", "", map[string]string{
+		"username": username,
+		"password": testPassword,
+	})
+
+	user, err := s.store.Users().GetByEmailOrUsername(context.Background(), "", username)
+	if err != nil {
+		s.t.Fatalf("load %s: %v", username, err)
+	}
+	return user, data["token"].(string)
+}
+
+// signUpOwner registers a user with owner rights
+func (s *testServer) signUpOwner(username string) (*models.User, string) {
+	s.t.Helper()
+	user, token := s.signUp(username)
+	user.IsStaff = true
+	user.IsOwner = true
+	if err := s.store.Users().Update(context.Background(), user); err != nil {
+		s.t.Fatalf("promote %s: %v", username, err)
+	}
+	return user, token
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+	s.signUp("alice")
+
+	s.expect(http.StatusUnauthorized, http.MethodPost, "/auth/login", "", map[string]string{
+		"username": "alice",
+		"password": "wrong-password",
+	})
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/v1/devices", "", nil)
+}
+
+func TestRequestGuards(t *testing.T) {
+	s := newTestServer(t)
+	alice, aliceToken := s.signUp("alice")
+	bob, _ := s.signUp("bob")
+
+	recorder, _ := s.request(http.MethodGet, "/health", "", nil)
+	for header, want := range map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"Content-Security-Policy":   "frame-ancestors 'none'",
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+	} {
+		if got := recorder.Header().Get(header); got != want {
+			t.Errorf("got %s %q, want %q", header, got, want)
+		}
+	}
+
+	// A body that binds to the wrong types fails too, the message tells which check refused it
+	expectRefused := func(status int, message, path, token string, body interface{}) {
+		t.Helper()
+		recorder, _ := s.request(http.MethodPost, path, token, body)
+		var failure struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &failure)
+		if recorder.Code != status || failure.Message != message {
+			t.Fatalf("POST %s: got %d %q, want %d %q", path, recorder.Code, failure.Message, status, message)
+		}
+	}
+	const tooDeep, tooLarge = "Request body is nested too deeply", "Request body is too large"
+	deep := json.RawMessage(strings.Repeat("[", 40) + strings.Repeat("]", 40))
+	expectRefused(http.StatusBadRequest, tooDeep, "/auth/login", "", map[string]interface{}{"username": deep})
+	expectRefused(http.StatusRequestEntityTooLarge, tooLarge, "/auth/login", "", map[string]string{
+		"username": strings.Repeat("a", 65*1024),
+	})
+
+	// Posting messages raises the body limit, the depth limit still applies
+	data := s.expect(http.StatusCreated, http.MethodPost, "/v1/conversation", aliceToken, map[string]interface{}{
+		"members": []string{alice.ID.String(), bob.ID.String()},
+	})
+	messagesPath := "/v1/conversation/" + data["conversation"].(map[string]interface{})["ID"].(string) + "/messages"
+	s.expect(http.StatusCreated, http.MethodPost, messagesPath, aliceToken, map[string]string{"content": strings.Repeat("a", 65536)})
+	expectRefused(http.StatusBadRequest, tooDeep, messagesPath, aliceToken, map[string]interface{}{"content": deep})
+}
+
+func TestRevokedSessions(t *testing.T) {
+	s := newTestServer(t)
+	_, ownerToken := s.signUpOwner("owner")
+	alice, aliceToken := s.signUp("alice")
+
+	// Logging in right after the sessions were revoked works
+	s.expect(http.StatusOK, http.MethodPost, "/v1/admin/users/"+alice.ID.String()+"/logout", ownerToken, nil)
+	data := s.expect(http.StatusOK, http.MethodPost, "/auth/login", "", map[string]string{
+		"username": "alice",
+		"password": testPassword,
+	})
+	aliceToken = data["token"].(string)
+	s.expect(http.StatusOK, http.MethodGet, "/v1/devices", aliceToken, nil)
+
+	data = s.expect(http.StatusOK, http.MethodPatch, "/v1/user/"+alice.ID.String(), aliceToken, map[string]string{
+		"password":         "N3w-passw0rd!",
+		"current_password": testPassword,
+	})
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/v1/devices", aliceToken, nil)
+	s.expect(http.StatusOK, http.MethodGet, "/v1/devices", data["token"].(string), nil)
+}
+
+func TestUpdateUserDetails(t *testing.T) {
+	s := newTestServer(t)
+	_, ownerToken := s.signUpOwner("owner")
+	alice, aliceToken := s.signUp("alice")
+	_, bobToken := s.signUp("bob")
+	path := "/v1/user/" + alice.ID.String()
+
+	// Only the user themselves or an owner may change an account
+	s.expect(http.StatusForbidden, http.MethodPatch, path, bobToken, map[string]string{"password": "T4ken-over!"})
+	s.expect(http.StatusForbidden, http.MethodPatch, path, bobToken, map[string]string{"first_name": "Mallory"})
+
+	// Changing your own password takes the current one
+	s.expect(http.StatusForbidden, http.MethodPatch, path, aliceToken, map[string]string{"password": "N3w-passw0rd!"})
+	s.expect(http.StatusForbidden, http.MethodPatch, path, aliceToken, map[string]string{
+		"password":         "N3w-passw0rd!",
+		"current_password": "wrong-password",
+	})
+	data := s.expect(http.StatusOK, http.MethodPatch, path, aliceToken, map[string]string{"first_name": "Alicia"})
+	if data["first_name"] != "Alicia" || data["token"] != nil {
+		t.Fatalf("got %v, want the new first name and no token", data)
+	}
+
+	// Owners reset it without knowing it and get no token for the account
+	data = s.expect(http.StatusOK, http.MethodPatch, path, ownerToken, map[string]string{"password": "0wner-chosen!"})
+	if _, ok := data["token"]; ok {
+		t.Fatalf("owner got a token for another user's account")
+	}
+	s.expect(http.StatusOK, http.MethodPost, "/auth/login", "", map[string]string{
+		"username": "alice",
+		"password": "0wner-chosen!",
+	})
+}
+
+func TestConversationMessages(t *testing.T) {
+	s := newTestServer(t)
+	alice, aliceToken := s.signUp("alice")
+	bob, bobToken := s.signUp("bob")
+	carol, _ := s.signUp("carol")
+	_, daveToken := s.signUp("dave")
+	_, ownerToken := s.signUpOwner("owner")
+
+	data := s.expect(http.StatusCreated, http.MethodPost, "/v1/conversation", aliceToken, map[string]interface{}{
+		"name":     "team",
+		"is_group": true,
+		"members":  []string{alice.ID.String(), bob.ID.String(), carol.ID.String()},
+	})
+	conversationID := data["conversation"].(map[string]interface{})["ID"].(string)
+
+	s.expect(http.StatusCreated, http.MethodPost, "/v1/conversation/"+conversationID+"/messages", bobToken, map[string]string{"content": "hello"})
+	s.expect(http.StatusOK, http.MethodGet, "/v1/conversation/"+conversationID, aliceToken, nil)
+	s.expect(http.StatusForbidden, http.MethodPost, "/v1/conversation/"+conversationID+"/messages", daveToken, map[string]string{"content": "hi"})
+	s.expect(http.StatusForbidden, http.MethodGet, "/v1/conversation/"+conversationID, daveToken, nil)
+
+	// Conversation lists are private to their member and staff
+	s.expect(http.StatusOK, http.MethodGet, "/v1/conversations/member/"+bob.ID.String(), bobToken, nil)
+	s.expect(http.StatusForbidden, http.MethodGet, "/v1/conversations/member/"+bob.ID.String(), daveToken, nil)
+	s.expect(http.StatusOK, http.MethodGet, "/v1/conversations/member/"+bob.ID.String(), ownerToken, nil)
+
+	messages, err := s.store.Messages().ListBySender(context.Background(), bob.ID)
+	if err != nil || len(messages) != 1 || messages[0].Content != "hello" {
+		t.Fatalf("got messages %v (%v), want bob's single message", messages, err)
+	}
+}
+
+func TestDevices(t *testing.T) {
+	s := newTestServer(t)
+	_, aliceToken := s.signUp("alice")
+	_, bobToken := s.signUp("bob")
+
+	device := map[string]string{"platform": "android", "token": "push-token", "app_version": "1.0.0"}
+	first := s.expect(http.StatusCreated, http.MethodPost, "/v1/devices", aliceToken, device)
+	deviceID := first["device"].(map[string]interface{})["id"].(string)
+
+	// Registering the same token again keeps the device
+	device["app_version"] = "1.1.0"
+	again := s.expect(http.StatusCreated, http.MethodPost, "/v1/devices", aliceToken, device)
+	if id := again["device"].(map[string]interface{})["id"]; id != deviceID {
+		t.Fatalf("re-registering the token created device %v, want %s", id, deviceID)
+	}
+
+	data := s.expect(http.StatusOK, http.MethodGet, "/v1/devices", aliceToken, nil)
+	if devices := data["devices"].([]interface{}); len(devices) != 1 {
+		t.Fatalf("got %d devices, want 1", len(devices))
+	}
+
+	s.expect(http.StatusNotFound, http.MethodDelete, "/v1/devices/"+deviceID, bobToken, nil)
+	s.expect(http.StatusOK, http.MethodDelete, "/v1/devices/"+deviceID, aliceToken, nil)
+	s.expect(http.StatusNotFound, http.MethodDelete, "/v1/devices/"+deviceID, aliceToken, nil)
+}
+
+func TestWebhooks(t *testing.T) {
+	s := newTestServer(t)
+	_, ownerToken := s.signUpOwner("owner")
+	_, userToken := s.signUp("alice")
+
+	webhook := map[string]interface{}{"url": "https://example.com/hooks", "events": []string{"message.created"}}
+	s.expect(http.StatusForbidden, http.MethodPost, "/v1/admin/webhooks", userToken, webhook)
+	data := s.expect(http.StatusCreated, http.MethodPost, "/v1/admin/webhooks", ownerToken, webhook)
+	webhookID := data["webhook"].(map[string]interface{})["id"].(string)
+
+	data = s.expect(http.StatusOK, http.MethodGet, "/v1/admin/webhooks", ownerToken, nil)
+	if webhooks := data["webhooks"].([]interface{}); len(webhooks) != 1 {
+		t.Fatalf("got %d webhooks, want 1", len(webhooks))
+	}
+
+	delivery := models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: uuid.MustParse(webhookID),
+		EventID:        uuid.New(),
+		Event:          enums.EventMessageCreated,
+		Payload:        models.JSON(`{"type":"message.created"}`),
+		Status:         enums.DeliveryFailed,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := s.store.WebhookDeliveries().Create(context.Background(), delivery); err != nil {
+		t.Fatalf("create delivery: %v", err)
+	}
+
+	s.expect(http.StatusAccepted, http.MethodPost, "/v1/admin/webhook-deliveries/"+delivery.ID.String()+"/replay", ownerToken, nil)
+	data = s.expect(http.StatusOK, http.MethodGet, "/v1/admin/webhooks/"+webhookID+"/deliveries", ownerToken, nil)
+	if total := data["total"].(float64); total != 2 {
+		t.Fatalf("got %v deliveries after the replay, want 2", total)
+	}
+	data = s.expect(http.StatusOK, http.MethodGet, "/v1/admin/webhooks/"+webhookID+"/deliveries?status=pending", ownerToken, nil)
+	if total := data["total"].(float64); total != 1 {
+		t.Fatalf("got %v pending deliveries, want the replay only", total)
+	}
+
+	s.expect(http.StatusOK, http.MethodDelete, "/v1/admin/webhooks/"+webhookID, ownerToken, nil)
+	s.expect(http.StatusNotFound, http.MethodGet, "/v1/admin/webhooks/"+webhookID, ownerToken, nil)
+}
+
+func TestBotApiTokens(t *testing.T) {
+	s := newTestServer(t)
+	owner, ownerToken := s.signUpOwner("owner")
+	alice, _ := s.signUp("alice")
+
+	commands := make(chan string, 1)
+	botWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		commands <- string(body)
+	}))
+	defer botWebhook.Close()
+
+	data := s.expect(http.StatusCreated, http.MethodPost, "/v1/admin/bots", ownerToken, map[string]interface{}{
+		"username":     "helper",
+		"display_name": "Helper",
+		"webhook_url":  botWebhook.URL,
+		"commands":     []string{"ping"},
+	})
+	botID := data["bot"].(map[string]interface{})["id"].(string)
+
+	data = s.expect(http.StatusCreated, http.MethodPost, "/v1/admin/bots/"+botID+"/tokens", ownerToken, map[string]interface{}{
+		"name":   "writer",
+		"scopes": []string{"read", "write"},
+	})
+	writeToken := data["token"].(map[string]interface{})["token"].(string)
+	writeTokenID := data["token"].(map[string]interface{})["id"].(string)
+
+	data = s.expect(http.StatusCreated, http.MethodPost, "/v1/admin/bots/"+botID+"/tokens", ownerToken, map[string]interface{}{
+		"name":   "reader",
+		"scopes": []string{"read"},
+	})
+	readToken := data["token"].(map[string]interface{})["token"].(string)
+
+	data = s.expect(http.StatusCreated, http.MethodPost, "/v1/conversation", ownerToken, map[string]interface{}{
+		"name":     "with bot",
+		"is_group": true,
+		"members":  []string{owner.ID.String(), alice.ID.String(), botID},
+	})
+	conversationID := data["conversation"].(map[string]interface{})["ID"].(string)
+	messagesPath := "/v1/conversation/" + conversationID + "/messages"
+
+	// Slash commands typed by people are forwarded to the bot's webhook
+	s.expect(http.StatusCreated, http.MethodPost, messagesPath, ownerToken, map[string]string{"content": "/ping now"})
+	select {
+	case body := <-commands:
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &payload); err != nil || payload["command"] != "ping" || payload["args"] != "now" {
+			t.Fatalf("bot received %s, want the ping command", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("bot did not receive the command")
+	}
+
+	s.expect(http.StatusCreated, http.MethodPost, messagesPath, writeToken, map[string]string{"content": "pong"})
+	s.expect(http.StatusForbidden, http.MethodPost, messagesPath, readToken, map[string]string{"content": "pong"})
+	s.expect(http.StatusOK, http.MethodGet, "/v1/conversation/"+conversationID, readToken, nil)
+
+	s.expect(http.StatusOK, http.MethodDelete, "/v1/admin/bots/"+botID+"/tokens/"+writeTokenID, ownerToken, nil)
+	s.expect(http.StatusUnauthorized, http.MethodPost, messagesPath, writeToken, map[string]string{"content": "pong"})
+
+	s.expect(http.StatusOK, http.MethodDelete, "/v1/admin/bots/"+botID, ownerToken, nil)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/v1/conversation/"+conversationID, readToken, nil)
+	data = s.expect(http.StatusOK, http.MethodGet, "/v1/admin/bots", ownerToken, nil)
+	if bots := data["bots"].([]interface{}); len(bots) != 0 {
+		t.Fatalf("got %d bots after deleting the only one, want 0", len(bots))
+	}
+}
+
+func TestDataExports(t *testing.T) {
+	s := newTestServer(t)
+	_, aliceToken := s.signUp("alice")
+	_, bobToken := s.signUp("bob")
+
+	data := s.expect(http.StatusAccepted, http.MethodPost, "/v1/account/exports", aliceToken, nil)
+	exportID := data["id"].(string)
+
+	data = s.expect(http.StatusOK, http.MethodGet, "/v1/account/exports/"+exportID, aliceToken, nil)
+	if status := data["status"]; status != string(enums.ExportPending) {
+		t.Fatalf("got export status %v, want %s", status, enums.ExportPending)
+	}
+	s.expect(http.StatusNotFound, http.MethodGet, "/v1/account/exports/"+exportID, bobToken, nil)
+}
+
+func TestHardDeleteUser(t *testing.T) {
+	s := newTestServer(t)
+	_, ownerToken := s.signUpOwner("owner")
+	alice, aliceToken := s.signUp("alice")
+
+	s.expect(http.StatusCreated, http.MethodPost, "/v1/devices", aliceToken, map[string]string{"platform": "ios", "token": "alice-phone"})
+	s.expect(http.StatusAccepted, http.MethodPost, "/v1/account/exports", aliceToken, nil)
+
+	s.expect(http.StatusOK, http.MethodDelete, "/v1/admin/users/"+alice.ID.String(), ownerToken, nil)
+
+	if _, err := s.store.Users().GetByID(context.Background(), alice.ID); err != repositories.ErrNotFound {
+		t.Fatalf("got %v loading the erased user, want ErrNotFound", err)
+	}
+	exports, _ := s.store.DataExports().ListByUser(context.Background(), alice.ID)
+	if len(exports) != 0 {
+		t.Fatalf("got %d exports of the erased user, want 0", len(exports))
+	}
+	devices, _ := s.store.Devices().ListByUsers(context.Background(), []uuid.UUID{alice.ID})
+	if len(devices) != 0 {
+		t.Fatalf("got %d devices of the erased user, want 0", len(devices))
+	}
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/v1/devices", aliceToken, nil)
+}
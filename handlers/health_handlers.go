@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"banter/health"
+	"banter/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LivenessHandler reports that the process is up and serving requests
+// @Summary Liveness Probe
+// @Description Succeeds while the server can handle requests, without checking its dependencies
+// @Tags Health
+// @Produce json
+// @Success 200 {object} responses.SuccessBody
+// @Router /health/live [get]
+func (h *Handler) LivenessHandler(c *gin.Context) {
+	responses.Ok(c, gin.H{"status": health.StatusUp})
+}
+
+// ReadinessHandler reports whether the server's dependencies are usable
+// @Summary Readiness Probe
+// @Description Checks the database, pending migrations, export storage and the event broker, with the status and latency of each
+// @Tags Health
+// @Produce json
+// @Success 200 {object} responses.SuccessBody
+// @Failure 503 {object} responses.SuccessBody
+// @Router /health/ready [get]
+func (h *Handler) ReadinessHandler(c *gin.Context) {
+	ready, checks := health.Run(c.Request.Context(), health.ReadinessChecks())
+
+	if !ready {
+		responses.ServiceUnavailable(c, gin.H{"status": health.StatusDown, "checks": checks})
+		return
+	}
+
+	responses.Ok(c, gin.H{"status": health.StatusUp, "checks": checks})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"banter/bots"
+	"banter/constants/enums"
+	"banter/i18n"
+	"banter/models"
+	"banter/notifications"
+	"banter/responses"
+	"banter/schemas"
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SendMessageHandler posts a message to a conversation
+// @Summary Send Message
+// @Description Posts a message to a conversation the authenticated user or bot is a member of. Messages starting with a slash command are forwarded to the bots in the conversation that handle the command.
+// @Tags Conversation
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param message body schemas.SendMessageSchema true "Message data"
+// @Success 201 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 413 {object} responses.FailureBody
+// @Failure 429 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /conversation/{id}/messages [post]
+// @Security AuthorizationToken
+func (h *Handler) SendMessageHandler(c *gin.Context) {
+	var input schemas.SendMessageSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	conversationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Conversation ID", "Must be a valid UUID")
+		return
+	}
+
+	sender := currentUser(c)
+	message, err := h.messages.SendMessage(c.Request.Context(), sender, conversationID, input.Content)
+	if err != nil {
+		respondError(c, "Failed to send message", err)
+		return
+	}
+
+	h.runInBackground(c, func(ctx context.Context) {
+		notifications.NotifyConversation(ctx, conversationID, sender.ID, i18n.Verbatim(sender.Username), i18n.Verbatim(messagePreview(message.Content)))
+	})
+
+	// Bots only answer commands typed by people, which also keeps bots from triggering each other
+	if !sender.IsBot() {
+		h.runInBackground(c, func(ctx context.Context) {
+			bots.DispatchCommand(ctx, h.store, message)
+		})
+	}
+
+	responses.Created(c, gin.H{"message": messageDetails(message)})
+}
+
+// messageDetails builds the representation of a message
+func messageDetails(message *models.Message) gin.H {
+	return gin.H{
+		"id":              message.ID,
+		"conversation_id": message.ConversationID,
+		"sender_id":       message.SenderID,
+		"content":         message.Content,
+		"created_at":      message.CreatedAt,
+	}
+}
+
+// messagePreview shortens a message to fit a push notification
+func messagePreview(content string) string {
+	const maxLength = 200
+
+	runes := []rune(content)
+	if len(runes) <= maxLength {
+		return content
+	}
+	return string(runes[:maxLength-1]) + "…"
+}
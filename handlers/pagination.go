@@ -0,0 +1,10 @@
+package handlers
+
+// nextPageOf returns the next page number, or 0 when the current page is the last one
+func nextPageOf(page, limit int, total int64) (int, bool) {
+	totalPages := int((total + int64(limit) - 1) / int64(limit)) // Ceiling division
+	if page < totalPages {
+		return page + 1, true
+	}
+	return 0, false // No next page
+}
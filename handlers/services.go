@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"banter/constants/enums"
+	"banter/responses"
+	"banter/services"
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// internalErrorMessage is sent in place of errors that weren't meant for clients, such as those of
+// the database
+const internalErrorMessage = "Something went wrong, please try again later"
+
+// respondError sends the response matching an error returned by a service, a store or a model
+func respondError(c *gin.Context, title string, err error) {
+	var domainErr *services.Error
+	if !errors.As(err, &domainErr) {
+		respondFailure(c, title, internalErrorMessage, err)
+		return
+	}
+
+	switch domainErr.Kind {
+	case services.KindInvalid:
+		responses.BadRequest(c, errorCode(domainErr, enums.CodeInvalidInput), title, domainErr.Message)
+	case services.KindNotFound:
+		responses.NotFound(c, errorCode(domainErr, enums.CodeNotFound), title, domainErr.Message)
+	case services.KindForbidden:
+		responses.Forbidden(c, errorCode(domainErr, enums.CodeForbidden), title, domainErr.Message)
+	case services.KindConflict:
+		responses.Conflict(c, errorCode(domainErr, enums.CodeConflict), title, domainErr.Message)
+	default:
+		responses.InternalServerError(c, errorCode(domainErr, enums.CodeInternalError), title, domainErr.Message)
+	}
+}
+
+// errorCode returns the code of a domain error, or the fallback of its kind if it has none
+func errorCode(err *services.Error, fallback enums.ErrorCode) enums.ErrorCode {
+	if err.Code != "" {
+		return err.Code
+	}
+	return fallback
+}
+
+// respondFailure answers an unexpected error with a 500 carrying the given message. Errors caused by
+// the client going away or a query running past its deadline are answered with a 408 instead, they
+// say nothing about the health of the server.
+func respondFailure(c *gin.Context, title, message string, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || c.Request.Context().Err() != nil {
+		responses.RequestTimeout(c, enums.CodeRequestTimeout, title, "The request took too long to complete, please try again")
+		return
+	}
+	// Kept on the context so the request log line carries the error the client doesn't see
+	_ = c.Error(err)
+	responses.InternalServerError(c, enums.CodeInternalError, title, message)
+}
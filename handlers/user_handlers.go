@@ -1,9 +1,16 @@
 package handlers
 
 import (
+	"banter/constants/enums"
+	"banter/i18n"
 	"banter/models"
+	"banter/repositories"
 	"banter/responses"
 	"banter/schemas"
+	"banter/utils/config"
+	"banter/utils/jwt"
+	"errors"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -23,47 +30,31 @@ import (
 // @Failure 500 {object} responses.FailureBody
 // @Router /user/{id} [get]
 // @Security AuthorizationToken
-func GetUserDetailsHandler(c *gin.Context) {
+func (h *Handler) GetUserDetailsHandler(c *gin.Context) {
 	// Get user ID from URL parameter
 	idParam := c.Param("id")
 
 	// Convert ID to UUID
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		responses.BadRequest(c, "Invalid User ID", "User ID must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid User ID", "User ID must be a valid UUID")
 		return
 	}
 
 	// Fetch user by ID
-	user, err := models.GetUserByID(userID)
+	user, err := h.store.Users().GetByID(c.Request.Context(), userID)
 	if err != nil {
-		responses.NotFound(c, "User Not Found", "No user found with the given ID")
+		responses.NotFound(c, enums.CodeUserNotFound, "User Not Found", "No user found with the given ID")
 		return
 	}
 
 	// Respond with user details
-	responses.Ok(c, gin.H{
-		"id":            user.ID,
-		"username":      user.Username,
-		"email":         user.Email,
-		"first_name":    user.FirstName,
-		"last_name":     user.LastName,
-		"date_of_birth": user.DateOfBirth,
-		"gender":        user.Gender,
-		"mobile_number": user.MobileNumber,
-		"profile_photo": user.ProfilePhotoUrl,
-		"is_staff":      user.IsStaff,
-		"is_owner":      user.IsOwner,
-		"last_seen":     user.LastSeen,
-		"status":        user.Status,
-		"created_at":    user.CreatedAt,
-		"updated_at":    user.UpdatedAt,
-	})
+	responses.Ok(c, userDetails(user))
 }
 
 // UpdateUserDetailsHandler updates user details by ID
 // @Summary Update User Details
-// @Description Updates user details by user ID. Only the provided fields will be updated.
+// @Description Updates user details by user ID. Only the provided fields will be updated. Users may update their own account, owners any account. Changing your own password requires current_password and returns a new token as `token`. Changing a password revokes every session of the user.
 // @Tags User
 // @Accept json
 // @Produce json
@@ -71,18 +62,27 @@ func GetUserDetailsHandler(c *gin.Context) {
 // @Param user body schemas.UpdateUserSchema true "User update data"
 // @Success 200 {object} responses.SuccessBody "User details updated successfully"
 // @Failure 400 {object} responses.FailureBody "Invalid request data"
+// @Failure 403 {object} responses.FailureBody "Not your account, or wrong current password"
 // @Failure 404 {object} responses.FailureBody "User not found"
 // @Failure 500 {object} responses.FailureBody "Internal server error"
 // @Router /user/{id} [patch]
 // @Security AuthorizationToken
-func UpdateUserDetailsHandler(c *gin.Context) {
+func (h *Handler) UpdateUserDetailsHandler(c *gin.Context) {
 	// Get user ID from URL parameter
 	idParam := c.Param("id")
 
 	// Convert ID to UUID
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		responses.BadRequest(c, "Invalid User ID", "User ID must be a valid UUID")
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid User ID", "User ID must be a valid UUID")
+		return
+	}
+
+	// Users manage their own account, only owners may change someone else's
+	caller := currentUser(c)
+	ownAccount := caller != nil && caller.ID == userID
+	if !ownAccount && (caller == nil || !caller.IsOwner) {
+		responses.Forbidden(c, enums.CodeForbidden, "Forbidden", "You can only update your own account")
 		return
 	}
 
@@ -91,16 +91,21 @@ func UpdateUserDetailsHandler(c *gin.Context) {
 	// Bind JSON request body to input struct
 	if err := c.ShouldBindJSON(&updatedUserDataInput); err != nil {
 		// Improved error handling to show validation failures
-		responses.BadRequest(c, "Invalid Input", err.Error())
+		responses.ValidationFailed(c, err)
 		return
 	}
 
 	// Fetch user by ID
-	user, err := models.GetUserByID(userID)
+	user, err := h.store.Users().GetByID(c.Request.Context(), userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		responses.NotFound(c, enums.CodeUserNotFound, "User Not Found", "No user found with the given ID")
+		return
+	}
 	if err != nil {
-		responses.NotFound(c, "User Not Found", "No user found with the given ID")
+		respondError(c, "Failed to fetch user", err)
 		return
 	}
+	before := userDetails(user)
 
 	// Update only the provided fields
 	if updatedUserDataInput.Username != nil {
@@ -110,13 +115,24 @@ func UpdateUserDetailsHandler(c *gin.Context) {
 		user.Email = *updatedUserDataInput.Email
 	}
 	if updatedUserDataInput.Password != nil {
+		// A session alone isn't enough to change your own password, so a stolen token can't lock the owner out
+		if ownAccount {
+			current := updatedUserDataInput.CurrentPassword
+			if current == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(*current)) != nil {
+				responses.Forbidden(c, enums.CodeInvalidCredentials, "Authentication Error", "Current password is incorrect")
+				return
+			}
+		}
+
 		// Proceed with password hashing
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*updatedUserDataInput.Password), bcrypt.DefaultCost)
 		if err != nil {
-			responses.InternalServerError(c, "Failed to hash password", err.Error())
+			respondError(c, "Failed to hash password", err)
 			return
 		}
 		user.Password = string(hashedPassword)
+		user.PasswordResetRequired = false
+		user.RevokeSessions()
 	}
 	if updatedUserDataInput.FirstName != nil {
 		user.FirstName = *updatedUserDataInput.FirstName
@@ -127,7 +143,7 @@ func UpdateUserDetailsHandler(c *gin.Context) {
 	if updatedUserDataInput.DateOfBirth != nil {
 		dob, err := schemas.ParseDOB(*updatedUserDataInput.DateOfBirth)
 		if err != nil {
-			responses.BadRequest(c, "Invalid Input", err.Error())
+			responses.BadRequest(c, enums.CodeInvalidInput, "Invalid Input", err.Error())
 			return
 		}
 		user.DateOfBirth = dob
@@ -138,22 +154,51 @@ func UpdateUserDetailsHandler(c *gin.Context) {
 	if updatedUserDataInput.MobileNumber != nil {
 		user.MobileNumber = *updatedUserDataInput.MobileNumber
 	}
+	if updatedUserDataInput.Locale != nil {
+		if *updatedUserDataInput.Locale != "" && !i18n.IsSupported(*updatedUserDataInput.Locale) {
+			responses.BadRequest(c, enums.CodeInvalidInput, "Invalid Input",
+				i18n.Translate(c.Request.Context(), "locale must be one of {locales}", "locales", strings.Join(i18n.Locales(), ", ")))
+			return
+		}
+		user.Locale = *updatedUserDataInput.Locale
+	}
 
 	// Save updated user data
-	err = user.UpdateUser()
-	if err != nil {
-		responses.InternalServerError(c, "Data Updation Error", "Error updating data")
+	err = h.store.Users().Update(c.Request.Context(), user)
+	if errors.Is(err, repositories.ErrNotFound) {
+		responses.NotFound(c, enums.CodeUserNotFound, "User Not Found", "No user found with the given ID")
 		return
 	}
-
-	err = user.UpdateUser()
 	if err != nil {
-		responses.NotFound(c, "User Not Found", "No user found with the given ID")
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
 		return
 	}
 
+	after := userDetails(user)
+	if updatedUserDataInput.Password != nil {
+		before["password_changed"] = false
+		after["password_changed"] = true
+	}
+	h.recordAudit(c, nil, enums.AuditUserUpdated, "user", user.ID.String(), before, after)
+
+	// Changing your own password revoked your session too, hand out a new token so you stay signed in
+	details := userDetails(user)
+	if updatedUserDataInput.Password != nil && ownAccount {
+		tokenString, err := jwt.GenerateToken(user.ID.String(), user.SessionVersion, config.Configs.Auth.TokenValidityInHrs)
+		if err != nil {
+			responses.InternalServerError(c, enums.CodeInternalError, "Token Generation Error", "Failed to generate token")
+			return
+		}
+		details["token"] = tokenString
+	}
+
 	// Respond with user details
-	responses.Ok(c, gin.H{
+	responses.Ok(c, details)
+}
+
+// userDetails builds the public representation of a user
+func userDetails(user *models.User) gin.H {
+	return gin.H{
 		"id":            user.ID,
 		"username":      user.Username,
 		"email":         user.Email,
@@ -162,12 +207,16 @@ func UpdateUserDetailsHandler(c *gin.Context) {
 		"date_of_birth": user.DateOfBirth,
 		"gender":        user.Gender,
 		"mobile_number": user.MobileNumber,
+		"locale":        user.Locale,
 		"profile_photo": user.ProfilePhotoUrl,
 		"is_staff":      user.IsStaff,
 		"is_owner":      user.IsOwner,
 		"last_seen":     user.LastSeen,
 		"status":        user.Status,
+		"type":          user.Type,
 		"created_at":    user.CreatedAt,
 		"updated_at":    user.UpdatedAt,
-	})
+
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	}
 }
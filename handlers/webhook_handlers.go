@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/responses"
+	"banter/schemas"
+	"banter/webhooks"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateWebhookHandler registers a webhook subscription
+// @Summary Create Webhook
+// @Description Registers an endpoint receiving HMAC-SHA256 signed event payloads. A secret is generated when none is given and is only returned here.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param webhook body schemas.CreateWebhookSchema true "Webhook data"
+// @Success 201 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/webhooks [post]
+// @Security AuthorizationToken
+func (h *Handler) CreateWebhookHandler(c *gin.Context) {
+	var input schemas.CreateWebhookSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			respondError(c, "Failed to generate secret", err)
+			return
+		}
+		secret = generated
+	}
+
+	subscription := models.WebhookSubscription{
+		URL:         input.URL,
+		Secret:      secret,
+		IsActive:    true,
+		CreatedByID: currentUser(c).ID,
+	}
+	subscription.SetEvents(input.Events)
+
+	if err := h.store.WebhookSubscriptions().Create(c.Request.Context(), &subscription); err != nil {
+		respondError(c, "Failed to create webhook", err)
+		return
+	}
+
+	details := webhookDetails(&subscription)
+	details["secret"] = subscription.Secret
+	responses.Created(c, gin.H{"webhook": details})
+}
+
+// GetWebhooksHandler lists webhook subscriptions
+// @Summary Get Webhooks
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.SuccessBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/webhooks [get]
+// @Security AuthorizationToken
+func (h *Handler) GetWebhooksHandler(c *gin.Context) {
+	subscriptions, err := h.store.WebhookSubscriptions().List(c.Request.Context(), false)
+	if err != nil {
+		respondError(c, "Failed to fetch webhooks", err)
+		return
+	}
+
+	results := make([]gin.H, 0, len(subscriptions))
+	for i := range subscriptions {
+		results = append(results, webhookDetails(&subscriptions[i]))
+	}
+
+	responses.Ok(c, gin.H{"webhooks": results})
+}
+
+// GetWebhookHandler fetches a webhook subscription
+// @Summary Get Webhook
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Router /admin/webhooks/{id} [get]
+// @Security AuthorizationToken
+func (h *Handler) GetWebhookHandler(c *gin.Context) {
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	responses.Ok(c, gin.H{"webhook": webhookDetails(subscription)})
+}
+
+// UpdateWebhookHandler updates a webhook subscription
+// @Summary Update Webhook
+// @Description Updates the URL, events, secret or active flag of a webhook. Only the provided fields are updated.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body schemas.UpdateWebhookSchema true "Webhook update data"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/webhooks/{id} [patch]
+// @Security AuthorizationToken
+func (h *Handler) UpdateWebhookHandler(c *gin.Context) {
+	var input schemas.UpdateWebhookSchema
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	// Update only the provided fields
+	if input.URL != nil {
+		subscription.URL = *input.URL
+	}
+	if input.Events != nil {
+		subscription.SetEvents(input.Events)
+	}
+	if input.Secret != nil {
+		subscription.Secret = *input.Secret
+	}
+	if input.IsActive != nil {
+		subscription.IsActive = *input.IsActive
+	}
+
+	if err := h.store.WebhookSubscriptions().Update(c.Request.Context(), subscription); err != nil {
+		respondFailure(c, "Data Updation Error", "Error updating data", err)
+		return
+	}
+
+	responses.Ok(c, gin.H{"webhook": webhookDetails(subscription)})
+}
+
+// DeleteWebhookHandler deletes a webhook subscription
+// @Summary Delete Webhook
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/webhooks/{id} [delete]
+// @Security AuthorizationToken
+func (h *Handler) DeleteWebhookHandler(c *gin.Context) {
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.WebhookSubscriptions().Delete(c.Request.Context(), subscription); err != nil {
+		respondError(c, "Failed to delete webhook", err)
+		return
+	}
+
+	responses.Ok(c, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveriesHandler lists the delivery log of a webhook subscription
+// @Summary Get Webhook Deliveries
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Delivery status (pending, succeeded, failed)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Success 200 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Failure 500 {object} responses.FailureBody
+// @Router /admin/webhooks/{id}/deliveries [get]
+// @Security AuthorizationToken
+func (h *Handler) GetWebhookDeliveriesHandler(c *gin.Context) {
+	var input schemas.WebhookDeliveryFilterSchema
+
+	if err := c.ShouldBindQuery(&input); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	if input.Page < 1 {
+		input.Page = 1
+	}
+	if input.Limit < 1 {
+		input.Limit = 10
+	}
+
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveries, total, err := h.store.WebhookDeliveries().Filter(c.Request.Context(), subscription.ID, enums.WebhookDeliveryStatus(input.Status), input.Page, input.Limit)
+	if err != nil {
+		respondError(c, "Failed to fetch deliveries", err)
+		return
+	}
+
+	nextPage, hasNextPage := nextPageOf(input.Page, input.Limit, total)
+
+	responses.Ok(c, gin.H{
+		"deliveries":    deliveries,
+		"total":         total,
+		"current_page":  input.Page,
+		"next_page":     nextPage,
+		"has_next_page": hasNextPage,
+	})
+}
+
+// ReplayWebhookDeliveryHandler sends a past delivery again
+// @Summary Replay Webhook Delivery
+// @Description Schedules a new delivery of the same event payload to the same webhook
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 202 {object} responses.SuccessBody
+// @Failure 400 {object} responses.FailureBody
+// @Failure 403 {object} responses.FailureBody
+// @Failure 404 {object} responses.FailureBody
+// @Router /admin/webhook-deliveries/{id}/replay [post]
+// @Security AuthorizationToken
+func (h *Handler) ReplayWebhookDeliveryHandler(c *gin.Context) {
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Delivery ID", "Must be a valid UUID")
+		return
+	}
+
+	replay, err := webhooks.Replay(c.Request.Context(), h.store, deliveryID)
+	if err != nil {
+		responses.NotFound(c, enums.CodeDeliveryNotFound, "Delivery Not Found", "No delivery found with the given ID")
+		return
+	}
+
+	responses.Accepted(c, gin.H{"delivery": replay})
+}
+
+// loadWebhook fetches the webhook subscription from the id path parameter
+func (h *Handler) loadWebhook(c *gin.Context) (*models.WebhookSubscription, bool) {
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, enums.CodeInvalidID, "Invalid Webhook ID", "Must be a valid UUID")
+		return nil, false
+	}
+
+	subscription, err := h.store.WebhookSubscriptions().GetByID(c.Request.Context(), subscriptionID)
+	if err != nil {
+		responses.NotFound(c, enums.CodeWebhookNotFound, "Webhook Not Found", "No webhook found with the given ID")
+		return nil, false
+	}
+
+	return subscription, true
+}
+
+// webhookDetails builds the representation of a webhook subscription, leaving out the secret
+func webhookDetails(subscription *models.WebhookSubscription) gin.H {
+	return gin.H{
+		"id":            subscription.ID,
+		"url":           subscription.URL,
+		"events":        subscription.EventList(),
+		"is_active":     subscription.IsActive,
+		"created_by_id": subscription.CreatedByID,
+		"created_at":    subscription.CreatedAt,
+		"updated_at":    subscription.UpdatedAt,
+	}
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
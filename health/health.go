@@ -0,0 +1,138 @@
+package health
+
+import (
+	"banter/events"
+	"banter/stores"
+	"banter/utils/migrations"
+	"banter/workers"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds every check so a hanging dependency can't stall the probe
+const checkTimeout = 2 * time.Second
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check verifies that one dependency is usable
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Problem is a check failure whose message is safe to show to anyone calling the probe. Other errors
+// are only logged, they may reveal hosts, paths or driver details.
+type Problem string
+
+func (p Problem) Error() string {
+	return string(p)
+}
+
+// Result is the outcome of a single check
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessChecks returns the checks a server instance has to pass before it can take traffic
+func ReadinessChecks() []Check {
+	return []Check{
+		{Name: "database", Run: pingDatabase},
+		{Name: "migrations", Run: checkMigrations},
+		{Name: "storage", Run: checkStorage},
+		{Name: "broker", Run: events.Ping},
+	}
+}
+
+// Run runs the checks concurrently and reports whether all of them passed along with each result
+func Run(ctx context.Context, checks []Check) (bool, map[string]Result) {
+	results := make(map[string]Result, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, check)
+
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		if result.Status != StatusUp {
+			return false, results
+		}
+	}
+	return true, results
+}
+
+func run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	started := time.Now()
+	err := check.Run(ctx)
+	result := Result{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = "unavailable"
+		var problem Problem
+		if errors.As(err, &problem) {
+			result.Error = problem.Error()
+		} else {
+			slog.WarnContext(ctx, "Readiness check failed", "check", check.Name, "error", err)
+		}
+	}
+	return result
+}
+
+func pingDatabase(ctx context.Context) error {
+	sqlDB, err := stores.GetDb().DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// checkMigrations fails while migrations are pending, as the code may rely on schema changes that
+// haven't been applied yet
+func checkMigrations(ctx context.Context) error {
+	pending, err := migrations.Pending(stores.GetDb().WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return Problem(fmt.Sprintf("%d migrations are pending", len(pending)))
+	}
+	return nil
+}
+
+// checkStorage makes sure data export archives can be written
+func checkStorage(ctx context.Context) error {
+	if err := os.MkdirAll(workers.ExportDirectory(), 0o750); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(workers.ExportDirectory(), ".health-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,171 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the language texts are written in, and the one used when nothing better is known
+const DefaultLocale = "en"
+
+// Catalogs map the English text of a message to its translation, one file per locale. Texts missing
+// from a catalog are shown in English.
+//
+//go:embed locales
+var files embed.FS
+
+type localeKey struct{}
+
+var (
+	catalogs = map[string]map[string]string{}
+	locales  []string
+	matcher  language.Matcher
+)
+
+func init() {
+	entries, err := fs.ReadDir(files, "locales")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("invalid catalog %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+
+	// The default locale comes first, it is what the matcher falls back to
+	locales = []string{DefaultLocale}
+	for locale := range catalogs {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales[1:])
+
+	tags := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tags[i] = language.MustParse(locale)
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// Locales returns the supported locales, the default first
+func Locales() []string {
+	return append([]string(nil), locales...)
+}
+
+// IsSupported tells whether texts can be shown in the given locale
+func IsSupported(locale string) bool {
+	for _, supported := range locales {
+		if locale == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the supported locale that best fits an Accept-Language header
+func Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return locales[index]
+}
+
+// T translates text to the locale, replacing {name} placeholders by the values given as name, value
+// pairs
+func T(locale, text string, args ...string) string {
+	if translated, ok := catalogs[locale][text]; ok && translated != "" {
+		text = translated
+	}
+	if len(args) < 2 {
+		return text
+	}
+
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Translate translates text to the locale of the request the context belongs to
+func Translate(ctx context.Context, text string, args ...string) string {
+	return T(FromContext(ctx), text, args...)
+}
+
+// WithLocale returns a context whose texts are shown in the given locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale of the context, the default if it has none
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// SetLocale makes the rest of the request answer in the given locale
+func SetLocale(c *gin.Context, locale string) {
+	c.Request = c.Request.WithContext(WithLocale(c.Request.Context(), locale))
+	c.Header("Content-Language", locale)
+}
+
+// Middleware answers every request in the locale that best fits its Accept-Language header. Once
+// the user is authenticated their stored preference, if they have one, takes over.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		SetLocale(c, Match(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// Message is a text translated once the locale of its reader is known, such as a notification sent
+// to users who each read it in their own language
+type Message struct {
+	text     string
+	args     []string
+	verbatim bool
+}
+
+// NewMessage returns a message translated like T
+func NewMessage(text string, args ...string) Message {
+	return Message{text: text, args: args}
+}
+
+// Verbatim returns a message shown as is in every locale, for text written by users
+func Verbatim(text string) Message {
+	return Message{text: text, verbatim: true}
+}
+
+// In returns the message in the given locale
+func (m Message) In(locale string) string {
+	if m.verbatim {
+		return m.text
+	}
+	return T(locale, m.text, m.args...)
+}
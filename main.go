@@ -1,64 +1,265 @@
 package main
 
 import (
+	"banter/constants/enums"
+	"banter/events"
+	"banter/handlers"
+	"banter/i18n"
+	"banter/metrics"
 	"banter/middlewares"
+	"banter/notifications"
+	"banter/repositories"
 	"banter/responses"
-	"banter/routes/auth"
-	v1 "banter/routes/v1"
+	"banter/routes"
+	"banter/stores"
+	"banter/tracing"
 	"banter/utils/config"
 	"banter/utils/logger"
-	"banter/utils/migrations"
+	"banter/webhooks"
+	"banter/workers"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const usage = `Usage: banter [-config path] [command]
+
+Commands:
+  serve                    start the server (default)
+  migrate <command>        apply, revert, list or create database migrations
+  create-admin             create an owner account
+  reset-password <user>    set a new password and sign the user out everywhere
+  ban-user <user>          ban a user, optionally until a given time
+  export-user <user>       write a user's data export archive
+  config validate          check the configuration and exit
+
+The config file defaults to config.yaml, or BANTER_CONFIG when set. Settings are overridden by
+BANTER_* environment variables, e.g. BANTER_JWT_SECRET or BANTER_JWT_SECRET_FILE.
+Users are given by username or email. Run "banter <command> -h" for the flags of a command.`
+
 func init() {
-	// Setup logger and load config
+	// Setup logger
 	logger.SetupLogger()
-	config.LoadConfig()
-	migrations.RegisterAllModels()
 }
 
 // @securityDefinitions.apikey AuthorizationToken
 // @in header
 // @name Authorization
 func main() {
+	configPath := flag.String("config", os.Getenv("BANTER_CONFIG"), "path of the yaml config file")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	command, args := "serve", []string{}
+	if flag.NArg() > 0 {
+		command, args = flag.Arg(0), flag.Args()[1:]
+	}
+
+	// config validate reports problems itself, every other command needs a valid configuration
+	if command == "config" {
+		runConfigCommand(*configPath, args)
+		return
+	}
+	if err := config.LoadConfig(*configPath); err != nil {
+		logger.Logger.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	switch command {
+	case "serve":
+		serve()
+	case "migrate":
+		runMigrateCommand(args)
+	case "create-admin":
+		runCreateAdminCommand(args)
+	case "reset-password":
+		runResetPasswordCommand(args)
+	case "ban-user":
+		runBanUserCommand(args)
+	case "export-user":
+		runExportUserCommand(args)
+	case "help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// serve applies or checks migrations, then starts the background workers and the HTTP server
+func serve() {
+	// Commands keep plain text output, the server logs in the configured format
+	if err := logger.Configure(config.Configs.Logging.Format, config.Configs.Logging.Level, config.Configs.Server.Mode); err != nil {
+		logger.Logger.Fatalf("Failed to set up logging: %v", err)
+	}
+	if err := tracing.Setup(config.Configs.Tracing); err != nil {
+		logger.Logger.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	prepareDatabase()
+
 	// Setup gin server mode based on YAML config
 	switch config.Configs.Server.Mode {
 	case "release":
-		logger.Logger.Println("Starting server in release mode")
 		gin.SetMode(gin.ReleaseMode)
 	default:
-		logger.Logger.Println("Starting server in debug mode")
 		gin.SetMode(gin.DebugMode)
 	}
+	slog.Info("Starting server", "mode", gin.Mode())
 
-	// Set up the Gin router with default settings
-	router := gin.Default()
+	// Requests are logged through slog with their request ID instead of gin's own logger
+	router := gin.New()
+	router.Use(middlewares.RequestIDMiddleware(), middlewares.LoggingMiddleware(), middlewares.RecoveryMiddleware())
+	router.Use(tracing.Middleware())
+	router.Use(i18n.Middleware())
 
 	// Use CORS middleware for cross-origin requests
-	router.Use(middlewares.CORSMiddleware())
+	router.Use(middlewares.CORSMiddleware(config.Configs.Cors))
 
-	// Define the health check route
-	router.GET("/health", func(c *gin.Context) {
-		responses.Ok(c, nil)
-	})
+	if config.Configs.Metrics.Enabled {
+		router.Use(metrics.Middleware())
+	}
 
-	// Set trusted proxies (if needed)
+	// Client addresses are taken from X-Forwarded-For only when the request comes through a trusted proxy
 	router.ForwardedByClientIP = true
-	router.SetTrustedProxies([]string{"127.0.0.1"})
+	if err := router.SetTrustedProxies(config.Configs.Server.TrustedProxies); err != nil {
+		logger.Logger.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
-	// Register routes
-	auth.Routes(router.Group(auth.RouteGroupName))
-	v1.ApiDocRoutes(router.Group(v1.RouteGroupName))
-	v1.UserRoutes(router.Group(v1.RouteGroupName))
-	v1.ConversationRoutes(router.Group(v1.RouteGroupName))
+	// Register routes, the handlers read and write through the database store
+	store := repositories.NewGormStore(stores.GetDb())
+	handler := handlers.NewHandler(store)
+	routes.Register(router, handler)
+	if config.Configs.Metrics.Enabled {
+		router.GET("/metrics", metrics.Handler(config.Configs.Metrics.BearerToken))
+	}
 
 	// 404 handler
 	router.NoRoute(func(c *gin.Context) {
-		responses.NotFound(c, "Route not found", "The requested endpoint does not exist.")
+		responses.NotFound(c, enums.CodeRouteNotFound, "Route not found", "The requested endpoint does not exist.")
 	})
 
-	// Run the Gin server
-	router.Run(config.Configs.Server.Port)
+	// Start background workers, they keep running until the HTTP server has drained so requests
+	// still in flight can hand them work
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var backgroundWorkers sync.WaitGroup
+	notifications.Setup(store)
+	webhooks.StartDeliveryWorker(workerCtx, &backgroundWorkers, store)
+	events.Setup(workerCtx, &backgroundWorkers, store)
+	workers.StartDataExportWorker(workerCtx, &backgroundWorkers, store)
+	workers.StartAccountDeletionWorker(workerCtx, &backgroundWorkers, store)
+	if config.Configs.Metrics.Enabled {
+		registerMetrics(store)
+	}
+
+	cfg := config.Configs.Server
+	server := &http.Server{
+		Addr:         cfg.Port,
+		Handler:      router,
+		ReadTimeout:  time.Duration(cfg.ReadTimeoutInSecs) * time.Second,
+		WriteTimeout: time.Duration(cfg.WriteTimeoutInSecs) * time.Second,
+		IdleTimeout:  time.Duration(cfg.IdleTimeoutInSecs) * time.Second,
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Listening", "address", cfg.Port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Server failed", "error", err)
+			os.Exit(1)
+		}
+	case <-signals.Done():
+		slog.Info("Shutting down")
+	}
+	// A second signal skips the graceful shutdown
+	stopSignals()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutInSecs)*time.Second)
+	defer cancel()
+	shutdown(shutdownCtx, server, handler, stopWorkers, &backgroundWorkers)
+}
+
+// registerMetrics exposes the connection pool and the depth of every background queue
+func registerMetrics(store repositories.Store) {
+	if sqlDB, err := stores.GetDb().DB(); err == nil {
+		metrics.RegisterDatabase(sqlDB)
+	}
+
+	metrics.RegisterQueue("notifications", func() (int, error) {
+		if notifications.GetDispatcher() == nil {
+			return 0, nil
+		}
+		return notifications.GetDispatcher().QueueDepth(), nil
+	})
+	metrics.RegisterQueue("data_exports", func() (int, error) {
+		return workers.ExportQueueDepth(), nil
+	})
+	metrics.RegisterQueue("outbox", func() (int, error) {
+		count, err := store.Events().CountUnpublished(context.Background())
+		return int(count), err
+	})
+	metrics.RegisterQueue("webhook_deliveries", func() (int, error) {
+		count, err := store.WebhookDeliveries().CountPending(context.Background())
+		return int(count), err
+	})
+}
+
+// shutdown stops accepting requests and waits for those in flight and the work they left running,
+// then stops the background workers and flushes their queues, and finally closes the broker and the
+// database. Every step shares the deadline of ctx.
+//
+// The API has no long-lived realtime connections yet. Those would need a close frame right after
+// the HTTP server stops accepting requests.
+func shutdown(ctx context.Context, server *http.Server, handler *handlers.Handler, stopWorkers context.CancelFunc, backgroundWorkers *sync.WaitGroup) {
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP server did not drain in time", "error", err)
+	}
+	// Notifications and bot commands started by requests still need the dispatcher and the database
+	if err := handler.Wait(ctx); err != nil {
+		slog.Warn("Work started by requests did not finish in time", "error", err)
+	}
+
+	stopWorkers()
+	workersStopped := make(chan struct{})
+	go func() {
+		backgroundWorkers.Wait()
+		close(workersStopped)
+	}()
+	select {
+	case <-workersStopped:
+	case <-ctx.Done():
+		slog.Warn("Background workers did not stop in time")
+	}
+
+	if err := notifications.Shutdown(ctx); err != nil {
+		slog.Warn("Notification queue was not flushed in time", "error", err)
+	}
+	if err := events.Close(); err != nil {
+		slog.Error("Failed to close the event broker", "error", err)
+	}
+	if err := stores.Close(); err != nil {
+		slog.Error("Failed to close the database", "error", err)
+	}
+	if err := tracing.Shutdown(ctx); err != nil {
+		slog.Warn("Buffered spans were not exported in time", "error", err)
+	}
+
+	slog.Info("Shutdown complete")
 }
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"banter/constants/enums"
+	"banter/responses"
+	"crypto/subtle"
+	"database/sql"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "banter"
+
+// unmatchedRoute labels requests that matched no route, so scanners probing random paths can't
+// create a series per path
+const unmatchedRoute = "unmatched"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	messagesSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_sent_total",
+		Help:      "Messages sent to conversations, use rate() for messages per second.",
+	})
+
+	queues = &queueCollector{depths: map[string]func() (int, error){}}
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		messagesSent,
+		queues,
+	)
+}
+
+// Middleware records the count and latency of every request under its route template
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(started).Seconds())
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format. A non-empty bearer token has to be
+// sent by the scraper.
+func Handler(bearerToken string) gin.HandlerFunc {
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+	expected := []byte("Bearer " + bearerToken)
+
+	return func(c *gin.Context) {
+		if bearerToken != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			responses.Unauthorized(c, enums.CodeUnauthorized, "Authentication Error", "A valid metrics token is required")
+			return
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// RegisterDatabase exposes the connection pool statistics of the database
+func RegisterDatabase(db *sql.DB) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// RegisterQueue exposes the depth of a background queue, depth is called on every scrape
+func RegisterQueue(name string, depth func() (int, error)) {
+	queues.mu.Lock()
+	defer queues.mu.Unlock()
+	queues.depths[name] = depth
+}
+
+// MessageSent counts a message sent to a conversation
+func MessageSent() {
+	messagesSent.Inc()
+}
+
+var queueDepthDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "queue_depth"),
+	"Items waiting in a background queue.",
+	[]string{"queue"}, nil,
+)
+
+// queueCollector reads the depth of every queue when scraped rather than tracking each change
+type queueCollector struct {
+	mu     sync.Mutex
+	depths map[string]func() (int, error)
+}
+
+func (q *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+}
+
+func (q *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	names := make([]string, 0, len(q.depths))
+	for name := range q.depths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		depth, err := q.depths[name]()
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(queueDepthDesc, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(depth), name)
+	}
+}
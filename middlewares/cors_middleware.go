@@ -1,23 +1,159 @@
 package middlewares
 
 import (
+	"banter/constants/enums"
+	"banter/responses"
+	"banter/utils/config"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
-func CORSMiddleware() gin.HandlerFunc {
+// corsPolicy is the parsed cors configuration
+type corsPolicy struct {
+	allowAll         bool
+	origins          map[string]bool
+	wildcards        []originWildcard
+	methods          []string
+	allowMethods     string
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// originWildcard matches the subdomains of an origin such as https://*.example.com
+type originWildcard struct {
+	prefix string
+	suffix string
+}
+
+func (w originWildcard) matches(origin string) bool {
+	if !strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+		return false
+	}
+	subdomain := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+	return subdomain != "" && !strings.ContainsAny(subdomain, "/:@")
+}
+
+// CORSMiddleware answers cross-origin requests of the origins allowed by the configuration. Requests of
+// other origins get no CORS headers, so browsers don't hand the response to the page, and their
+// preflight requests are refused.
+func CORSMiddleware(cfg config.CorsConfig) gin.HandlerFunc {
+	policy := newCorsPolicy(cfg)
+
 	return func(c *gin.Context) {
-		// Allow specific origins, methods, and headers based on your requirements
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "127.0.0.1")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-
-		// Handle preflight requests (OPTIONS method) by setting status code and headers
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(200)
+		header := c.Writer.Header()
+		// Responses differ by origin, caches must not serve one origin's response to another
+		if !policy.allowAll || policy.allowCredentials {
+			header.Add("Vary", "Origin")
+		}
+
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if origin == "" {
+			c.Next()
 			return
 		}
 
-		// Continue processing the request
-		c.Next()
+		if !policy.allows(origin) {
+			if preflight {
+				responses.Forbidden(c, enums.CodeOriginNotAllowed, "Origin Not Allowed", "Cross-origin requests from this origin are not allowed")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if preflight {
+			method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+			if !slices.Contains(policy.methods, method) {
+				responses.Forbidden(c, enums.CodeMethodNotAllowed, "Method Not Allowed", "Cross-origin requests with this method are not allowed")
+				c.Abort()
+				return
+			}
+		}
+
+		if policy.allowAll && !policy.allowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if policy.allowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if policy.exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", policy.exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", policy.allowMethods)
+		if policy.allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", policy.allowHeaders)
+		}
+		if policy.maxAge != "" {
+			header.Set("Access-Control-Max-Age", policy.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func newCorsPolicy(cfg config.CorsConfig) corsPolicy {
+	policy := corsPolicy{
+		origins:          map[string]bool{},
+		allowHeaders:     strings.Join(cfg.AllowedHeaders, ", "),
+		exposeHeaders:    strings.Join(cfg.ExposedHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			policy.allowAll = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://*.")
+			policy.wildcards = append(policy.wildcards, originWildcard{prefix: scheme + "://", suffix: "." + host})
+		default:
+			policy.origins[origin] = true
+		}
+	}
+
+	for _, method := range cfg.AllowedMethods {
+		policy.methods = append(policy.methods, strings.ToUpper(method))
+	}
+	policy.allowMethods = strings.Join(policy.methods, ", ")
+
+	if cfg.MaxAgeInSecs > 0 {
+		policy.maxAge = strconv.Itoa(cfg.MaxAgeInSecs)
+	}
+	return policy
+}
+
+func (p corsPolicy) allows(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, wildcard := range p.wildcards {
+		if wildcard.matches(origin) {
+			return true
+		}
 	}
+	return false
 }
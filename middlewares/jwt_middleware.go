@@ -1,19 +1,36 @@
 package middlewares
 
 import (
+	"banter/constants/enums"
+	"banter/i18n"
+	"banter/models"
+	"banter/repositories"
 	"banter/responses"
+	"banter/utils/apitoken"
 	"banter/utils/config"
+	jwtutil "banter/utils/jwt"
+	"banter/utils/logger"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-func JWTMiddleware() gin.HandlerFunc {
+// JWTMiddleware authenticates the request with a JWT or a bot API token, loading users from the given store.
+// The user's ID is added to the request context so every log line of the request carries it.
+func JWTMiddleware(store repositories.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		users := store.Users()
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			responses.Unauthorized(c, "Missing auth header", "Authorization header is missing")
+			responses.Unauthorized(c, enums.CodeUnauthorized, "Missing auth header", "Authorization header is missing")
 			c.Abort()
 			return
 		}
@@ -21,46 +38,180 @@ func JWTMiddleware() gin.HandlerFunc {
 		// Ensure the token is prefixed with 'Bearer '
 		authParts := strings.Split(authHeader, " ")
 		if len(authParts) != 2 || authParts[0] != "Bearer" {
-			responses.Unauthorized(c, "Invalid auth header format", "Authorization header must be in 'Bearer <token>' format")
+			responses.Unauthorized(c, enums.CodeUnauthorized, "Invalid auth header format", "Authorization header must be in 'Bearer <token>' format")
 			c.Abort()
 			return
 		}
 
 		tokenString := authParts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Ensure the token uses the expected signing method (HMAC in this case)
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(config.Configs.Jwt.Secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			responses.Unauthorized(c, "Invalid token", err.Error())
+		// Bots authenticate with API tokens, everyone else with a JWT
+		var user *models.User
+		var ok bool
+		if apitoken.IsApiToken(tokenString) {
+			user, ok = authenticateApiToken(c, store, tokenString)
+		} else {
+			user, ok = authenticateJWT(c, users, tokenString)
+		}
+		if !ok {
 			c.Abort()
 			return
 		}
+		userID := user.ID.String()
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			responses.Unauthorized(c, "Invalid token claims", "Unable to parse token claims")
+		// A language the user picked outranks the Accept-Language of the request
+		if user.Locale != "" && i18n.IsSupported(user.Locale) {
+			i18n.SetLocale(c, user.Locale)
+		}
+
+		switch user.EffectiveStatus() {
+		case enums.UserBanned:
+			responses.Forbidden(c, enums.CodeAccountBanned, "Account Banned", "User account is banned")
+			c.Abort()
+			return
+		case enums.UserInactive:
+			responses.Unauthorized(c, enums.CodeAccountInactive, "Account Inactive", "User account is inactive")
 			c.Abort()
 			return
 		}
 
-		// Extract user information or other claims from the token
-		userID, ok := claims["user_id"].(string) // Use user_id instead of username for generalization
-		if !ok {
-			responses.Unauthorized(c, "Invalid user claim", "User ID claim in token is invalid")
+		// Users flagged for a password reset may only change their own password
+		if user.PasswordResetRequired && !isOwnUserUpdate(c, userID) {
+			responses.Forbidden(c, enums.CodePasswordResetRequired, "Password Reset Required", "Please update your password to continue")
 			c.Abort()
 			return
 		}
 
+		// Keep presence fresh without writing on every request
+		if user.LastSeen == nil || time.Since(*user.LastSeen) > 30*time.Second {
+			if err := users.TouchLastSeen(c.Request.Context(), user.ID); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to update last seen", "user_id", user.ID, "error", err)
+			}
+		}
+
 		// Set user info in context
 		c.Set("user_id", userID)
+		c.Set("user", user)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), userID))
 
 		// Continue with the request processing
 		c.Next()
 	}
 }
+
+// authenticateJWT loads the user a JWT was issued to, responding with an error if the token is invalid
+// or its session was revoked
+func authenticateJWT(c *gin.Context, users repositories.UserRepository, tokenString string) (*models.User, bool) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Ensure the token uses the expected signing method (HMAC in this case)
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(config.Configs.Jwt.Secret), nil
+	})
+
+	if err != nil || !token.Valid {
+		responses.Unauthorized(c, enums.CodeInvalidToken, "Invalid token", "Token is invalid or has expired")
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		responses.Unauthorized(c, enums.CodeInvalidToken, "Invalid token claims", "Unable to parse token claims")
+		return nil, false
+	}
+
+	// Extract user information or other claims from the token
+	userID, ok := claims["user_id"].(string) // Use user_id instead of username for generalization
+	if !ok {
+		responses.Unauthorized(c, enums.CodeInvalidToken, "Invalid user claim", "User ID claim in token is invalid")
+		return nil, false
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		responses.Unauthorized(c, enums.CodeInvalidToken, "Invalid user claim", "User ID claim in token is invalid")
+		return nil, false
+	}
+
+	// Load the user so revoked sessions and restricted accounts are rejected
+	user, err := users.GetByID(c.Request.Context(), parsedUserID)
+	if err != nil {
+		responses.Unauthorized(c, enums.CodeInvalidToken, "Invalid token", "User no longer exists")
+		return nil, false
+	}
+
+	// Tokens issued before session versions existed count as the first version
+	sessionVersion, _ := claims[jwtutil.SessionVersionClaim].(float64)
+	if user.IsSessionRevoked(int(sessionVersion)) {
+		responses.Unauthorized(c, enums.CodeSessionRevoked, "Session Revoked", "Token has been revoked, please login again")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// authenticateApiToken loads the bot an API token belongs to. Besides validating the token it checks
+// that the token's scopes allow the request and applies the bot's rate limit.
+func authenticateApiToken(c *gin.Context, store repositories.Store, tokenString string) (*models.User, bool) {
+	token, err := store.ApiTokens().GetByHash(c.Request.Context(), apitoken.Hash(tokenString))
+	if err != nil || !token.IsUsable(time.Now()) {
+		responses.Unauthorized(c, enums.CodeInvalidToken, "Invalid token", "API token is invalid, expired or revoked")
+		return nil, false
+	}
+
+	bot, err := store.Bots().GetByUserID(c.Request.Context(), token.UserID)
+	if err != nil || !bot.User.IsBot() {
+		responses.Unauthorized(c, enums.CodeInvalidToken, "Invalid token", "Bot no longer exists")
+		return nil, false
+	}
+	user := &bot.User
+
+	// A forced logout revokes the API tokens created before it
+	if user.SessionsRevokedAfter(token.CreatedAt) {
+		responses.Unauthorized(c, enums.CodeSessionRevoked, "Session Revoked", "API token has been revoked")
+		return nil, false
+	}
+
+	if required := requiredScope(c.Request.Method); !token.HasScope(required) {
+		responses.Forbidden(c, enums.CodeInsufficientScope, "Insufficient Scope", i18n.Translate(c.Request.Context(), "API token lacks the {scope} scope", "scope", string(required)))
+		return nil, false
+	}
+
+	limit := bot.RateLimitPerMinute
+	if limit <= 0 {
+		limit = config.Configs.Bots.RateLimitPerMinute
+	}
+	if limit <= 0 {
+		limit = 60
+	}
+	if allowed, retryAfter := botLimiter.allow(user.ID, limit, time.Now()); !allowed {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		responses.TooManyRequests(c, enums.CodeRateLimited, "Rate Limit Exceeded", "Too many requests, please slow down")
+		return nil, false
+	}
+
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > 30*time.Second {
+		if err := store.ApiTokens().Touch(c.Request.Context(), token.ID); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update last use of API token", "api_token_id", token.ID, "error", err)
+		}
+	}
+
+	c.Set("api_token", token)
+	return user, true
+}
+
+// requiredScope returns the API token scope needed for a request method
+func requiredScope(method string) enums.TokenScope {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return enums.ScopeRead
+	default:
+		return enums.ScopeWrite
+	}
+}
+
+// isOwnUserUpdate reports whether the request updates the authenticated user's own details
+func isOwnUserUpdate(c *gin.Context, userID string) bool {
+	return c.Request.Method == http.MethodPatch && c.FullPath() == "/v1/user/:id" && c.Param("id") == userID
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"banter/constants/enums"
+	"banter/responses"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoggingMiddleware logs every request once it has been handled. It runs after RequestIDMiddleware and
+// reads the request context after the handlers, so the line also carries the authenticated user.
+func LoggingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", float64(time.Since(started).Microseconds()) / 1000,
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+		slog.Log(c.Request.Context(), level, "Request handled", attrs...)
+	}
+}
+
+// RecoveryMiddleware turns a panic in a handler into a 500 response and logs it with its stack trace
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "Handler panicked", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		responses.InternalServerError(c, enums.CodeInternalError, "Internal Server Error", "Something went wrong")
+		c.Abort()
+	})
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// rateLimiter is an in-memory token bucket limiter keyed by user. Limits apply per server instance.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[uuid.UUID]*bucket
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+var botLimiter = &rateLimiter{buckets: map[uuid.UUID]*bucket{}}
+
+// allow takes a token from the key's bucket, which holds up to perMinute tokens and refills at
+// perMinute tokens a minute. When the bucket is empty it returns how long until a token is available.
+func (l *rateLimiter) allow(key uuid.UUID, perMinute int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	capacity := float64(perMinute)
+	refillPerSecond := capacity / 60
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.updatedAt).Seconds() * refillPerSecond
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / refillPerSecond * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
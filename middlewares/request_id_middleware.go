@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"banter/utils/logger"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID that ties together every log line of a request
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern keeps IDs sent by clients or proxies short and free of characters that could
+// forge log fields
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware reuses the request ID sent by the client or a proxy, or generates one. The ID is
+// echoed in the response and added to the request context for logging.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
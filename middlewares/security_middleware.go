@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"banter/utils/config"
+	"banter/utils/jsonguard"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestBodyKey holds the request body as it came in, before any limit was put on it
+const requestBodyKey = "request_body"
+
+// SecurityHeadersMiddleware sets the headers that keep browsers from sniffing content types, framing
+// the API in other sites and, once they have seen it over HTTPS, talking to it over plain HTTP
+func SecurityHeadersMiddleware(cfg config.SecurityConfig) gin.HandlerFunc {
+	frameAncestors := "'none'"
+	if len(cfg.FrameAncestors) > 0 {
+		frameAncestors = strings.Join(cfg.FrameAncestors, " ")
+	}
+	csp := "frame-ancestors " + frameAncestors
+
+	hsts := ""
+	if cfg.HstsMaxAgeInSecs > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HstsMaxAgeInSecs)
+		if cfg.HstsIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Content-Security-Policy", csp)
+		// Browsers ignore it on plain HTTP, so it is sent whether or not TLS ends at a proxy
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// BodyLimitMiddleware limits request bodies to limitInKb, 0 leaves the limit as it is. Reading past
+// the limit fails with an *http.MaxBytesError, which binding answers with a 413. Used on a route it
+// replaces the limit set for all routes, so a route can accept larger bodies than the rest.
+func BodyLimitMiddleware(limitInKb int) gin.HandlerFunc {
+	limit := int64(limitInKb) * 1024
+
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		// The limit applies to the body as sent, not to one already limited for all routes
+		body, _ := c.Get(requestBodyKey)
+		original, ok := body.(io.ReadCloser)
+		if !ok {
+			original = c.Request.Body
+			c.Set(requestBodyKey, original)
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, original, limit)
+		c.Next()
+	}
+}
+
+// JSONDepthMiddleware makes reading a request body fail with jsonguard.ErrTooDeep once the JSON in it
+// nests objects and arrays deeper than maxDepth, which binding answers with a 400. 0 leaves the depth
+// unchecked. It has to run before BodyLimitMiddleware, whose limits then apply to the checked body.
+func JSONDepthMiddleware(maxDepth int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxDepth > 0 && c.Request.Body != nil {
+			c.Request.Body = jsonguard.NewReader(c.Request.Body, maxDepth)
+		}
+		c.Next()
+	}
+}
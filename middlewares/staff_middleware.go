@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaffMiddleware only lets staff and owner accounts through, it must run after JWTMiddleware
+func StaffMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("user")
+		user, ok := value.(*models.User)
+		if !exists || !ok {
+			responses.Unauthorized(c, enums.CodeUnauthorized, "Unauthorized", "Authenticated user is missing")
+			c.Abort()
+			return
+		}
+
+		if !user.IsStaff && !user.IsOwner {
+			responses.Forbidden(c, enums.CodeForbidden, "Forbidden", "Staff access is required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"banter/stores"
+	"banter/utils/config"
+	"banter/utils/logger"
+	"banter/utils/migrations"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+const migrateUsage = `Usage: banter migrate <command>
+
+Commands:
+  up [n]         apply pending migrations, all of them unless n is given
+  down [n]       revert the last n applied migrations (default 1)
+  status         list migrations and whether they are applied
+  create <name>  add empty up and down files for a new migration`
+
+// runMigrateCommand runs a migrate subcommand and exits the process on failure
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(stores.GetDb(), stepsArg(args[1:], 0))
+		for _, migration := range applied {
+			fmt.Printf("Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			logger.Logger.Fatalf("Migrating up failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		reverted, err := migrations.Down(stores.GetDb(), stepsArg(args[1:], 1))
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			logger.Logger.Fatalf("Migrating down failed: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+	case "status":
+		statuses, err := migrations.Status(stores.GetDb())
+		if err != nil {
+			logger.Logger.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%d_%-40s %s\n", s.Version, s.Name, applied)
+		}
+	case "create":
+		flags := flag.NewFlagSet("migrate create", flag.ExitOnError)
+		directory := flags.String("dir", migrations.SourceDirectory, "directory holding a folder of migrations per driver")
+		flags.Parse(args[1:])
+		if flags.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: banter migrate create [-dir directory] <name>")
+			os.Exit(2)
+		}
+
+		paths, err := migrations.Create(*directory, flags.Arg(0), time.Now())
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+		if err != nil {
+			logger.Logger.Fatalf("Failed to create migration: %v", err)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
+
+// stepsArg parses the optional number of migrations to apply or revert
+func stepsArg(args []string, fallback int) int {
+	if len(args) == 0 {
+		return fallback
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		fmt.Fprintln(os.Stderr, "The number of migrations must be a positive integer")
+		os.Exit(2)
+	}
+	return steps
+}
+
+// prepareDatabase applies pending migrations when stores.migrate_on_start is set, otherwise it exits
+// if any are pending
+func prepareDatabase() {
+	if config.Configs.Stores.MigrateOnStart {
+		applied, err := migrations.Up(stores.GetDb(), 0)
+		if err != nil {
+			logger.Logger.Fatalf("Failed to apply migrations: %v", err)
+		}
+		for _, migration := range applied {
+			slog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+		}
+		return
+	}
+
+	pending, err := migrations.Pending(stores.GetDb())
+	if err != nil {
+		logger.Logger.Fatalf("Failed to check migrations: %v", err)
+	}
+	// Serving on an outdated schema fails on the first query touching what the migrations add
+	if len(pending) > 0 {
+		logger.Logger.Fatalf("%d migrations are pending, run \"banter migrate up\" or set stores.migrate_on_start to apply them", len(pending))
+	}
+}
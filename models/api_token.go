@@ -0,0 +1,58 @@
+package models
+
+import (
+	"banter/constants/enums"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApiToken is a long-lived credential for a bot account. Only a hash of the token is stored.
+type ApiToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	Scopes     string     `gorm:"type:varchar(100);not null" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+}
+
+// ScopeList returns the scopes granted to the token
+func (t *ApiToken) ScopeList() []enums.TokenScope {
+	var scopes []enums.TokenScope
+	for _, scope := range strings.Split(t.Scopes, ",") {
+		if scope != "" {
+			scopes = append(scopes, enums.TokenScope(scope))
+		}
+	}
+	return scopes
+}
+
+// SetScopes stores the scopes granted to the token
+func (t *ApiToken) SetScopes(scopes []enums.TokenScope) {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+	t.Scopes = strings.Join(values, ",")
+}
+
+// HasScope reports whether the token was granted the scope
+func (t *ApiToken) HasScope(scope enums.TokenScope) bool {
+	for _, granted := range t.ScopeList() {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUsable reports whether the token is neither revoked nor expired at the given time
+func (t *ApiToken) IsUsable(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || t.ExpiresAt.After(now))
+}
@@ -3,12 +3,13 @@ package models
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Attachment struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
-	MessageID uint           `gorm:"not null" json:"message_id"`
+	MessageID uuid.UUID      `gorm:"type:uuid;not null;index" json:"message_id"`
 	FilePath  string         `gorm:"type:varchar(2048)"`
 	FileType  string         `gorm:"type:varchar(10)"` // image, video or document
 	FileUrl   string         `gorm:"type:varchar(1024)"`
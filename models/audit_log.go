@@ -0,0 +1,46 @@
+package models
+
+import (
+	"banter/constants/enums"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrAuditLogImmutable = errors.New("audit log entries are append-only")
+
+// AuditLog records a security relevant action. Entries are never updated or deleted.
+type AuditLog struct {
+	ID         uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	ActorID    *uuid.UUID        `gorm:"type:uuid;index" json:"actor_id"`
+	Action     enums.AuditAction `gorm:"type:varchar(50);not null;index" json:"action"`
+	TargetType string            `gorm:"type:varchar(50);index" json:"target_type"`
+	TargetID   string            `gorm:"type:varchar(64);index" json:"target_id"`
+	IPAddress  string            `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent  string            `gorm:"type:varchar(512)" json:"user_agent"`
+	Before     JSON              `json:"before,omitempty" swaggertype:"object"`
+	After      JSON              `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt  time.Time         `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+}
+
+// AuditLogFilter holds the optional criteria used to query the audit log
+type AuditLogFilter struct {
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   string
+	Action     enums.AuditAction
+	From       time.Time
+	To         time.Time
+}
+
+// BeforeUpdate keeps the audit log append-only
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete keeps the audit log append-only
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bot holds the bot specific settings of a bot user account.
+type Bot struct {
+	UserID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OwnerID            uuid.UUID `gorm:"type:uuid;not null;index"`
+	Description        string    `gorm:"type:varchar(500)"`
+	WebhookURL         string    `gorm:"type:varchar(2048)"`
+	WebhookSecret      string    `gorm:"type:varchar(128);not null"`
+	Commands           string    `gorm:"type:varchar(1024)"`
+	RateLimitPerMinute int       `gorm:"default:0"`
+	CreatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP;index"`
+	UpdatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP;index"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// CommandList returns the slash commands the bot handles
+func (b *Bot) CommandList() []string {
+	if b.Commands == "" {
+		return []string{}
+	}
+	return strings.Split(b.Commands, ",")
+}
+
+// SetCommands stores the slash commands the bot handles
+func (b *Bot) SetCommands(commands []string) {
+	normalised := make([]string, 0, len(commands))
+	for _, command := range commands {
+		normalised = append(normalised, strings.ToLower(strings.TrimPrefix(command, "/")))
+	}
+	b.Commands = strings.Join(normalised, ",")
+}
+
+// HandlesCommand reports whether the bot registered the slash command
+func (b *Bot) HandlesCommand(command string) bool {
+	for _, registered := range b.CommandList() {
+		if registered == strings.ToLower(command) {
+			return true
+		}
+	}
+	return false
+}
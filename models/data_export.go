@@ -0,0 +1,23 @@
+package models
+
+import (
+	"banter/constants/enums"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataExport tracks an asynchronous export of a user's data
+type DataExport struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID          `gorm:"type:uuid;not null;index"`
+	Status      enums.ExportStatus `gorm:"type:varchar(15);index"`
+	FilePath    string             `gorm:"type:varchar(2048)"`
+	Error       string             `gorm:"type:varchar(1024)"`
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time     `gorm:"index"`
+	CreatedAt   time.Time      `gorm:"default:CURRENT_TIMESTAMP;index"`
+	UpdatedAt   time.Time      `gorm:"default:CURRENT_TIMESTAMP;index"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" swaggerignore:"true"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"banter/constants/enums"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device is a push notification target registered by a user. A user may have several devices.
+type Device struct {
+	ID         uuid.UUID            `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID            `gorm:"type:uuid;not null;index" json:"user_id"`
+	Platform   enums.DevicePlatform `gorm:"type:varchar(10);not null" json:"platform"`
+	Token      string               `gorm:"type:varchar(4096);not null;uniqueIndex" json:"-"`
+	AppVersion string               `gorm:"type:varchar(32)" json:"app_version"`
+	CreatedAt  time.Time            `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+	UpdatedAt  time.Time            `gorm:"default:CURRENT_TIMESTAMP;index" json:"updated_at"`
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type Message struct {
-	ID             uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey"`
 	ConversationID uuid.UUID      `gorm:"type:uuid;not null;index"`
 	SenderID       uuid.UUID      `gorm:"type:uuid;not null;index"`
 	Content        string         `gorm:"type:varchar(65536)"`
@@ -19,3 +19,10 @@ type Message struct {
 	Conversation Conversation `gorm:"foreignKey:ConversationID"`
 	Sender       User         `gorm:"foreignKey:SenderID"`
 }
+
+// AfterCreate keeps the conversation's last activity time in step with its newest message
+func (m *Message) AfterCreate(tx *gorm.DB) error {
+	return tx.Model(&Conversation{}).
+		Where("id = ? AND last_message_at < ?", m.ConversationID, m.CreatedAt).
+		UpdateColumn("last_message_at", m.CreatedAt).Error
+}
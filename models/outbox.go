@@ -0,0 +1,50 @@
+package models
+
+import (
+	"banter/constants/enums"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change it describes.
+// The relay publishes it to the broker once the transaction has committed.
+type OutboxEvent struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primaryKey"`
+	Type          enums.EventType `gorm:"type:varchar(64);not null;index"`
+	AggregateType string          `gorm:"type:varchar(32);not null"`
+	AggregateID   uuid.UUID       `gorm:"type:uuid;not null;index"`
+	Payload       JSON            `gorm:"not null"`
+	Attempts      int             `gorm:"default:0"`
+	LastError     string          `gorm:"type:varchar(1024)"`
+	NextAttemptAt time.Time       `gorm:"index"`
+	PublishedAt   *time.Time      `gorm:"index"`
+	CreatedAt     time.Time       `gorm:"default:CURRENT_TIMESTAMP;index"`
+}
+
+// ProcessedEvent records that a consumer has handled an event so redeliveries are skipped
+type ProcessedEvent struct {
+	Consumer    string    `gorm:"type:varchar(64);primaryKey"`
+	EventID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProcessedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// NewOutboxEvent builds an event about the given aggregate with the payload encoded as JSON
+func NewOutboxEvent(eventType enums.EventType, aggregateType string, aggregateID uuid.UUID, payload interface{}) (*OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &OutboxEvent{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
@@ -2,7 +2,6 @@ package models
 
 import (
 	"banter/constants/enums"
-	"banter/stores"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,77 +9,77 @@ import (
 )
 
 type User struct {
-	ID               uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Username         string           `gorm:"unique;not null;index"`
-	Email            string           `gorm:"unique;not null;index"`
-	Password         string           `gorm:"not null"`
-	FirstName        string           `gorm:"type:varchar(50)"`
-	LastName         string           `gorm:"type:varchar(50)"`
-	DateOfBirth      time.Time        `gorm:"type:date"`
-	Gender           string           `gorm:"type:varchar(12)"`
-	MobileNumber     string           `gorm:"type:varchar(15);index"`
-	ProfilePhotoPath string           `gorm:"type:varchar(1024);"`
-	ProfilePhotoUrl  string           `gorm:"type:varchar(1024);"`
-	IsStaff          bool             `gorm:"default:false;index"`
-	IsOwner          bool             `gorm:"default:false;index"`
-	LastSeen         *time.Time       `gorm:"type:timestamp;index"`
-	Status           enums.UserStatus `gorm:"type:varchar(15);index"`
-	CreatedAt        time.Time        `gorm:"default:CURRENT_TIMESTAMP;index"`
-	UpdatedAt        time.Time        `gorm:"default:CURRENT_TIMESTAMP;index"`
-	DeletedAt        gorm.DeletedAt   `gorm:"index" swaggerignore:"true"`
+	ID                    uuid.UUID        `gorm:"type:uuid;primaryKey"`
+	Username              string           `gorm:"unique;not null;index"`
+	Email                 string           `gorm:"unique;not null;index"`
+	Password              string           `gorm:"not null" json:"-"`
+	FirstName             string           `gorm:"type:varchar(50)"`
+	LastName              string           `gorm:"type:varchar(50)"`
+	DateOfBirth           time.Time        `gorm:"type:date"`
+	Gender                string           `gorm:"type:varchar(12)"`
+	MobileNumber          string           `gorm:"type:varchar(15);index"`
+	ProfilePhotoPath      string           `gorm:"type:varchar(1024);"`
+	ProfilePhotoUrl       string           `gorm:"type:varchar(1024);"`
+	IsStaff               bool             `gorm:"default:false;index"`
+	IsOwner               bool             `gorm:"default:false;index"`
+	LastSeen              *time.Time       `gorm:"type:timestamp;index"`
+	Status                enums.UserStatus `gorm:"type:varchar(15);index"`
+	Type                  enums.UserType   `gorm:"type:varchar(10);default:human;index"`
+	StatusReason          string           `gorm:"type:varchar(500)"`
+	StatusExpiresAt       *time.Time
+	SessionsRevokedAt     *time.Time
+	SessionVersion        int            `gorm:"not null;default:0"`
+	PasswordResetRequired bool           `gorm:"default:false"`
+	Locale                string         `gorm:"type:varchar(16)"`
+	DeletionScheduledAt   *time.Time     `gorm:"index"`
+	CreatedAt             time.Time      `gorm:"default:CURRENT_TIMESTAMP;index"`
+	UpdatedAt             time.Time      `gorm:"default:CURRENT_TIMESTAMP;index"`
+	DeletedAt             gorm.DeletedAt `gorm:"index" swaggerignore:"true"`
 }
 
-// GetUserByID retrieves a user by ID
-func GetUserByID(id uuid.UUID) (*User, error) {
-	var user User
-	if err := stores.GetDb().First(&user, "id = ?", id).Error; err != nil {
-		return nil, err
-	}
-	return &user, nil
+// UserFilter holds the optional criteria used to list users
+type UserFilter struct {
+	Status      enums.UserStatus
+	IsStaff     *bool
+	CreatedFrom time.Time
+	CreatedTo   time.Time
 }
 
-// CreateUser creates a new user
-func (u *User) CreateUser() error {
-	u.ID = uuid.New()
-	return stores.GetDb().Create(u).Error
+// IsBot reports whether the user is a bot account
+func (u *User) IsBot() bool {
+	return u.Type == enums.UserTypeBot
 }
 
-// UpdateUser updates an existing user
-func (u *User) UpdateUser() error {
-	return stores.GetDb().Save(u).Error
+// EffectiveStatus returns the user status taking an expired ban or deactivation into account
+func (u *User) EffectiveStatus() enums.UserStatus {
+	if u.Status != enums.UserActive && u.StatusExpiresAt != nil && u.StatusExpiresAt.Before(time.Now()) {
+		return enums.UserActive
+	}
+	return u.Status
 }
 
-// DeleteUser marks the user as inactive
-func (u *User) DeleteUser() error {
-	return stores.GetDb().Delete(u).Error
+// SetStatus changes the user status and records why and until when it applies
+func (u *User) SetStatus(status enums.UserStatus, reason string, expiresAt *time.Time) {
+	u.Status = status
+	u.StatusReason = reason
+	u.StatusExpiresAt = expiresAt
 }
 
-// GetAllUsers retrieves all users
-func GetAllUsers() ([]User, error) {
-	var users []User
-	if err := stores.GetDb().Find(&users).Error; err != nil {
-		return nil, err
-	}
-	return users, nil
+// RevokeSessions invalidates every token issued to the user so far. Tokens carry the session version
+// they were issued for, so those issued after the user is saved stay valid however soon they follow.
+func (u *User) RevokeSessions() {
+	revokedAt := time.Now()
+	u.SessionsRevokedAt = &revokedAt
+	u.SessionVersion++
 }
 
-// GetUserByEmailOrUsername retrieves a user by email or username
-func GetUserByEmailOrUsername(email, username string, user *User) error {
-	db := stores.GetDb()
-
-	if email != "" {
-		err := db.Where("email = ?", email).First(user).Error
-		if err == nil {
-			return nil
-		}
-	}
-
-	if username != "" {
-		err := db.Where("username = ?", username).First(user).Error
-		if err == nil {
-			return nil
-		}
-	}
+// IsSessionRevoked reports whether a token issued for the given session version has been revoked
+func (u *User) IsSessionRevoked(sessionVersion int) bool {
+	return sessionVersion < u.SessionVersion
+}
 
-	return gorm.ErrRecordNotFound
+// SessionsRevokedAfter reports whether the user's sessions were revoked after the given time, which
+// revokes the bot API tokens created until then
+func (u *User) SessionsRevokedAfter(createdAt time.Time) bool {
+	return u.SessionsRevokedAt != nil && createdAt.Before(*u.SessionsRevokedAt)
 }
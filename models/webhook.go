@@ -0,0 +1,61 @@
+package models
+
+import (
+	"banter/constants/enums"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookSubscription is an endpoint that receives signed event payloads
+type WebhookSubscription struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	URL         string         `gorm:"type:varchar(2048);not null"`
+	Secret      string         `gorm:"type:varchar(128);not null"`
+	Events      string         `gorm:"type:varchar(1024);not null"` // comma separated, * for every event
+	IsActive    bool           `gorm:"default:true;index"`
+	CreatedByID uuid.UUID      `gorm:"type:uuid;index"`
+	CreatedAt   time.Time      `gorm:"default:CURRENT_TIMESTAMP;index"`
+	UpdatedAt   time.Time      `gorm:"default:CURRENT_TIMESTAMP;index"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" swaggerignore:"true"`
+}
+
+// WebhookDelivery is one attempt sequence of sending an event to a subscription
+type WebhookDelivery struct {
+	ID             uuid.UUID                   `gorm:"type:uuid;primaryKey" json:"id"`
+	SubscriptionID uuid.UUID                   `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	EventID        uuid.UUID                   `gorm:"type:uuid;not null;index" json:"event_id"`
+	Event          enums.EventType             `gorm:"type:varchar(64);not null;index" json:"event"`
+	Payload        JSON                        `gorm:"not null" json:"payload" swaggertype:"object"`
+	Status         enums.WebhookDeliveryStatus `gorm:"type:varchar(15);not null;index" json:"status"`
+	Attempts       int                         `gorm:"default:0" json:"attempts"`
+	NextAttemptAt  time.Time                   `gorm:"index" json:"next_attempt_at"`
+	ResponseStatus int                         `json:"response_status"`
+	LastError      string                      `gorm:"type:varchar(1024)" json:"last_error"`
+	DeliveredAt    *time.Time                  `json:"delivered_at"`
+	ReplayOfID     *uuid.UUID                  `gorm:"type:uuid" json:"replay_of_id,omitempty"`
+	CreatedAt      time.Time                   `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+	UpdatedAt      time.Time                   `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// EventList returns the events the subscription listens to
+func (s *WebhookSubscription) EventList() []string {
+	return strings.Split(s.Events, ",")
+}
+
+// SetEvents stores the events the subscription listens to
+func (s *WebhookSubscription) SetEvents(events []string) {
+	s.Events = strings.Join(events, ",")
+}
+
+// Subscribes reports whether the subscription listens to the event
+func (s *WebhookSubscription) Subscribes(event enums.EventType) bool {
+	for _, subscribed := range s.EventList() {
+		if subscribed == string(enums.WebhookAllEvents) || subscribed == string(event) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionHost  = "https://api.push.apple.com"
+	apnsDevelopmentHost = "https://api.sandbox.push.apple.com"
+
+	// APNs rejects provider tokens older than an hour, refresh well before that
+	apnsTokenLifetime = 40 * time.Minute
+)
+
+// APNsProvider sends notifications through the Apple Push Notification service using a token
+// based (.p8) authentication key
+type APNsProvider struct {
+	keyID    string
+	teamID   string
+	bundleID string
+	host     string
+	key      *ecdsa.PrivateKey
+	client   *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsProvider creates a provider from an APNs auth key file
+func NewAPNsProvider(keyFile, keyID, teamID, bundleID string, production bool) (*APNsProvider, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+
+	host := apnsDevelopmentHost
+	if production {
+		host = apnsProductionHost
+	}
+
+	return &APNsProvider{
+		keyID:    keyID,
+		teamID:   teamID,
+		bundleID: bundleID,
+		host:     host,
+		key:      key,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *APNsProvider) Name() string {
+	return "apns"
+}
+
+func (p *APNsProvider) Send(ctx context.Context, notification Notification) error {
+	token, err := p.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for key, value := range notification.Data {
+		payload[key] = value
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/3/device/"+notification.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "bearer "+token)
+	request.Header.Set("apns-topic", p.bundleID)
+	request.Header.Set("apns-push-type", "alert")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	_ = json.Unmarshal(responseBody, &result)
+
+	// 410 means the app was uninstalled, BadDeviceToken means the token never was valid here
+	if response.StatusCode == http.StatusGone || result.Reason == "BadDeviceToken" || result.Reason == "Unregistered" {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("apns responded with %d: %s", response.StatusCode, result.Reason)
+}
+
+// providerToken returns a cached signed provider token, signing a new one when it gets old
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Since(p.issuedAt) < apnsTokenLifetime {
+		return p.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.keyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+
+	p.token = signed
+	p.issuedAt = now
+	return p.token, nil
+}
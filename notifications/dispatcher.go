@@ -0,0 +1,155 @@
+package notifications
+
+import (
+	"banter/constants/enums"
+	"banter/repositories"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	sendTimeout  = 10 * time.Second
+	maxRetryWait = 5 * time.Minute
+)
+
+// retryDelay is the wait before the first retry, doubled for every further one. Tests shorten it.
+var retryDelay = time.Second
+
+type job struct {
+	notification Notification
+	attempt      int
+}
+
+// Dispatcher queues notifications and delivers them through the provider of each device platform.
+// Failed deliveries are retried with exponential backoff and devices whose token is rejected are
+// removed.
+type Dispatcher struct {
+	store      repositories.Store
+	providers  map[enums.DevicePlatform]Provider
+	queue      chan job
+	maxRetries int
+	done       chan struct{}
+	stopOnce   sync.Once
+	workers    sync.WaitGroup
+}
+
+func NewDispatcher(store repositories.Store, providers map[enums.DevicePlatform]Provider, queueSize, maxRetries int) *Dispatcher {
+	return &Dispatcher{
+		store:      store,
+		providers:  providers,
+		queue:      make(chan job, queueSize),
+		maxRetries: maxRetries,
+		done:       make(chan struct{}),
+	}
+}
+
+// Start launches the given number of delivery workers
+func (d *Dispatcher) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		d.workers.Add(1)
+		go func() {
+			defer d.workers.Done()
+			for {
+				select {
+				case j := <-d.queue:
+					d.deliver(j)
+				case <-d.done:
+					d.drain()
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Stop delivers the notifications already queued and stops the workers. Retries scheduled after
+// that are dropped. It returns early with the context's error if the queue isn't drained in time.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.done) })
+
+	stopped := make(chan struct{})
+	go func() {
+		d.workers.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain delivers what is left in the queue without waiting for more
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case j := <-d.queue:
+			d.deliver(j)
+		default:
+			return
+		}
+	}
+}
+
+// Enqueue queues a notification for delivery, returning false if the queue is full
+func (d *Dispatcher) Enqueue(notification Notification) bool {
+	return d.enqueue(job{notification: notification})
+}
+
+// QueueDepth returns the number of notifications waiting to be delivered
+func (d *Dispatcher) QueueDepth() int {
+	return len(d.queue)
+}
+
+func (d *Dispatcher) enqueue(j job) bool {
+	select {
+	case d.queue <- j:
+		return true
+	default:
+		slog.Warn("Notification queue is full, dropping notification", "platform", j.notification.Platform)
+		return false
+	}
+}
+
+func (d *Dispatcher) deliver(j job) {
+	provider, ok := d.providers[j.notification.Platform]
+	if !ok {
+		// No push service configured for this platform
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	err := provider.Send(ctx, j.notification)
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, ErrInvalidToken) {
+		if err := d.store.Devices().DeleteByToken(ctx, j.notification.Token); err != nil {
+			slog.Error("Failed to remove dead device token", "provider", provider.Name(), "error", err)
+		}
+		return
+	}
+
+	if j.attempt >= d.maxRetries {
+		slog.Warn("Giving up on notification", "provider", provider.Name(), "attempts", j.attempt+1, "error", err)
+		return
+	}
+
+	// Retry with exponential backoff
+	delay := retryDelay << j.attempt
+	if delay > maxRetryWait {
+		delay = maxRetryWait
+	}
+	j.attempt++
+	time.AfterFunc(delay, func() {
+		d.enqueue(j)
+	})
+}
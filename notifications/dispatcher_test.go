@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/repositories"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	retryDelay = 10 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+// startDispatcher runs a dispatcher delivering android notifications through the fake provider
+func startDispatcher(t *testing.T, store repositories.Store, fake *FakeProvider, maxRetries int) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(store, map[enums.DevicePlatform]Provider{enums.PlatformAndroid: fake}, 10, maxRetries)
+	d.Start(1)
+	t.Cleanup(func() { d.Stop(context.Background()) })
+	return d
+}
+
+// eventually fails the test unless ok becomes true within a second
+func eventually(t *testing.T, what string, ok func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !ok() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcherRetriesFailedSends(t *testing.T) {
+	fake := NewFakeProvider()
+	fake.FailNext = 2
+	d := startDispatcher(t, repositories.NewMemoryStore(), fake, 3)
+
+	d.Enqueue(Notification{Token: "phone", Platform: enums.PlatformAndroid, Title: "hi"})
+	eventually(t, "the retried notification", func() bool { return len(fake.Sent()) == 1 })
+}
+
+func TestDispatcherGivesUpAfterMaxRetries(t *testing.T) {
+	fake := NewFakeProvider()
+	fake.FailNext = 3
+	d := startDispatcher(t, repositories.NewMemoryStore(), fake, 2)
+
+	d.Enqueue(Notification{Token: "phone", Platform: enums.PlatformAndroid, Title: "hi"})
+	eventually(t, "every attempt", func() bool {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return fake.FailNext == 0
+	})
+
+	// The first send and two retries failed, there is no fourth attempt
+	time.Sleep(10 * retryDelay)
+	if sent := fake.Sent(); len(sent) != 0 {
+		t.Fatalf("got %d notifications sent, want none", len(sent))
+	}
+}
+
+func TestDispatcherRemovesInvalidTokens(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	userID := uuid.New()
+	for _, token := range []string{"dead-phone", "live-phone"} {
+		device := &models.Device{UserID: userID, Platform: enums.PlatformAndroid, Token: token}
+		if err := store.Devices().Register(ctx, device); err != nil {
+			t.Fatalf("register device: %v", err)
+		}
+	}
+
+	fake := NewFakeProvider()
+	fake.InvalidTokens["dead-phone"] = true
+	d := startDispatcher(t, store, fake, 3)
+
+	d.Enqueue(Notification{Token: "dead-phone", Platform: enums.PlatformAndroid})
+	eventually(t, "the dead device to be removed", func() bool {
+		devices, err := store.Devices().ListByUsers(ctx, []uuid.UUID{userID})
+		return err == nil && len(devices) == 1 && devices[0].Token == "live-phone"
+	})
+	if sent := fake.Sent(); len(sent) != 0 {
+		t.Fatalf("got %d notifications sent to an invalid token, want none", len(sent))
+	}
+}
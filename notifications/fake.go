@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// errFakeFailure is returned for the sends a FakeProvider was told to fail
+var errFakeFailure = errors.New("fake provider failure")
+
+// FakeProvider logs and records notifications in memory instead of delivering them, selected with
+// notifications.provider: fake. Tokens added to InvalidTokens are rejected with ErrInvalidToken and
+// the next FailNext sends fail with an error that is retried.
+type FakeProvider struct {
+	mu            sync.Mutex
+	sent          []Notification
+	InvalidTokens map[string]bool
+	FailNext      int
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{InvalidTokens: map[string]bool{}}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Send(ctx context.Context, notification Notification) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.InvalidTokens[notification.Token] {
+		return ErrInvalidToken
+	}
+	if p.FailNext > 0 {
+		p.FailNext--
+		return errFakeFailure
+	}
+	p.sent = append(p.sent, notification)
+	slog.InfoContext(ctx, "Fake notification", "platform", notification.Platform, "title", notification.Title, "body", notification.Body)
+	return nil
+}
+
+// Sent returns the notifications recorded so far
+func (p *FakeProvider) Sent() []Notification {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sent := make([]Notification, len(p.sent))
+	copy(sent, p.sent)
+	return sent
+}
@@ -0,0 +1,142 @@
+package notifications
+
+import (
+	"banter/constants/enums"
+	"banter/i18n"
+	"banter/repositories"
+	"banter/utils/config"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var dispatcher *Dispatcher
+
+// Setup creates the dispatcher with the configured provider and starts it. The push provider sends
+// through the push services present in the config, the fake one only logs notifications. The users
+// and devices to notify are looked up in the given store.
+func Setup(store repositories.Store) {
+	cfg := config.Configs.Notifications
+	providers := map[enums.DevicePlatform]Provider{}
+
+	switch cfg.Provider {
+	case "fake":
+		fake := NewFakeProvider()
+		providers[enums.PlatformAndroid] = fake
+		providers[enums.PlatformIOS] = fake
+		providers[enums.PlatformWeb] = fake
+	default:
+		setupPushProviders(cfg, providers)
+	}
+
+	d := NewDispatcher(store, providers, valueOrDefault(cfg.QueueSize, 1000), valueOrDefault(cfg.MaxRetries, 5))
+	d.Start(valueOrDefault(cfg.Workers, 4))
+	SetDispatcher(d)
+}
+
+// setupPushProviders adds FCM and APNs for the platforms they are configured for
+func setupPushProviders(cfg config.NotificationsConfig, providers map[enums.DevicePlatform]Provider) {
+	if cfg.Fcm.CredentialsFile != "" {
+		fcm, err := NewFCMProvider(cfg.Fcm.CredentialsFile)
+		if err != nil {
+			slog.Warn("Push notifications for android and web devices are disabled", "error", err)
+		} else {
+			providers[enums.PlatformAndroid] = fcm
+			providers[enums.PlatformWeb] = fcm
+		}
+	}
+
+	if cfg.Apns.KeyFile != "" {
+		apns, err := NewAPNsProvider(cfg.Apns.KeyFile, cfg.Apns.KeyID, cfg.Apns.TeamID, cfg.Apns.BundleID, cfg.Apns.Production)
+		if err != nil {
+			slog.Warn("Push notifications for ios devices are disabled", "error", err)
+		} else {
+			providers[enums.PlatformIOS] = apns
+		}
+	}
+}
+
+// SetDispatcher replaces the dispatcher notifications are sent through, e.g. with one backed by a
+// FakeProvider
+func SetDispatcher(d *Dispatcher) {
+	dispatcher = d
+}
+
+// GetDispatcher returns the dispatcher notifications are sent through
+func GetDispatcher() *Dispatcher {
+	return dispatcher
+}
+
+// NotifyConversation notifies the members of a conversation about activity by the sender. Members
+// who muted the conversation are skipped.
+func NotifyConversation(ctx context.Context, conversationID, senderID uuid.UUID, title, body i18n.Message) {
+	if dispatcher == nil {
+		return
+	}
+
+	memberIDs, err := dispatcher.store.Members().ListNotifiableIDs(ctx, conversationID, senderID)
+	if err != nil {
+		slog.Error("Failed to fetch members to notify", "conversation_id", conversationID, "error", err)
+		return
+	}
+
+	NotifyUsers(ctx, memberIDs, title, body, map[string]string{"conversation_id": conversationID.String()})
+}
+
+// NotifyUsers sends a notification to every device of the given users, in the language each of them
+// picked. Users that are currently online are skipped as they already see the activity in the app.
+func NotifyUsers(ctx context.Context, userIDs []uuid.UUID, title, body i18n.Message, data map[string]string) {
+	if dispatcher == nil || len(userIDs) == 0 {
+		return
+	}
+
+	onlineWindow := time.Duration(valueOrDefault(config.Configs.Notifications.OnlineWindowInSecs, 60)) * time.Second
+	offlineIDs, err := dispatcher.store.Users().ListOfflineIDs(ctx, userIDs, time.Now().Add(-onlineWindow))
+	if err != nil {
+		slog.Error("Failed to fetch presence of users to notify", "error", err)
+		return
+	}
+
+	devices, err := dispatcher.store.Devices().ListByUsers(ctx, offlineIDs)
+	if err != nil {
+		slog.Error("Failed to fetch devices of users to notify", "error", err)
+		return
+	}
+
+	locales, err := dispatcher.store.Users().Locales(ctx, offlineIDs)
+	if err != nil {
+		slog.Error("Failed to fetch locales of users to notify", "error", err)
+		return
+	}
+
+	for _, device := range devices {
+		locale := locales[device.UserID]
+		if !i18n.IsSupported(locale) {
+			locale = i18n.DefaultLocale
+		}
+		dispatcher.Enqueue(Notification{
+			Token:    device.Token,
+			Platform: device.Platform,
+			Title:    title.In(locale),
+			Body:     body.In(locale),
+			Data:     data,
+		})
+	}
+}
+
+// Shutdown flushes the queued notifications and stops the dispatcher
+func Shutdown(ctx context.Context) error {
+	if dispatcher == nil {
+		return nil
+	}
+	return dispatcher.Stop(ctx)
+}
+
+func valueOrDefault(value, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,26 @@
+package notifications
+
+import (
+	"banter/constants/enums"
+	"context"
+	"errors"
+)
+
+// ErrInvalidToken is returned by a provider when the device token is no longer valid and the
+// device should be forgotten
+var ErrInvalidToken = errors.New("device token is no longer valid")
+
+// Notification is a push notification addressed to a single device
+type Notification struct {
+	Token    string
+	Platform enums.DevicePlatform
+	Title    string
+	Body     string
+	Data     map[string]string
+}
+
+// Provider delivers notifications through a push service such as FCM or APNs
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, notification Notification) error
+}
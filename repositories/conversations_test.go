@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/utils/migrations"
+	"bytes"
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteStore returns a gorm store on a fresh SQLite database with every migration applied
+func newSQLiteStore(t *testing.T) *GormStore {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "banter.db") + "?_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if _, err := migrations.Up(db, 0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return NewGormStore(db)
+}
+
+// listedConversation is a conversation as it appears in a page of ListForUser
+type listedConversation struct {
+	ID       uuid.UUID
+	IsPinned bool
+}
+
+func TestListForUserPagination(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tied := base.Add(-time.Hour)
+
+	// Pinned conversations come first even when their last message is older
+	type seed struct {
+		id            uuid.UUID
+		lastMessageAt time.Time
+		pinned        bool
+	}
+	seeds := []seed{
+		{uuid.New(), base.Add(-48 * time.Hour), true},
+		{uuid.New(), base.Add(-24 * time.Hour), true},
+		{uuid.New(), base, false},
+		{uuid.New(), tied, false},
+		{uuid.New(), tied, false},
+		{uuid.New(), tied, false},
+		{uuid.New(), tied, false},
+		{uuid.New(), base.Add(-72 * time.Hour), false},
+	}
+	alice := models.User{ID: uuid.New(), Username: "alice", Email: "alice@example.com", Password: "hash"}
+	bob := models.User{ID: uuid.New(), Username: "bob", Email: "bob@example.com", Password: "hash"}
+	bobsConversation := uuid.New()
+
+	// The expected order is (is_pinned, last_message_at, id) descending
+	expected := make([]listedConversation, 0, len(seeds))
+	sorted := append([]seed(nil), seeds...)
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].pinned != sorted[b].pinned {
+			return sorted[a].pinned
+		}
+		if !sorted[a].lastMessageAt.Equal(sorted[b].lastMessageAt) {
+			return sorted[a].lastMessageAt.After(sorted[b].lastMessageAt)
+		}
+		return bytes.Compare(sorted[a].id[:], sorted[b].id[:]) > 0
+	})
+	for _, s := range sorted {
+		expected = append(expected, listedConversation{ID: s.id, IsPinned: s.pinned})
+	}
+
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"gorm":   func(t *testing.T) Store { return newSQLiteStore(t) },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+
+			for _, user := range []models.User{alice, bob} {
+				if err := store.Users().Create(ctx, &user); err != nil {
+					t.Fatalf("create user: %v", err)
+				}
+			}
+			for _, s := range seeds {
+				conversation := &models.Conversation{ID: s.id, IsGroup: true, LastMessageAt: s.lastMessageAt}
+				if err := store.Conversations().Create(ctx, conversation); err != nil {
+					t.Fatalf("create conversation: %v", err)
+				}
+				if err := store.Members().Add(ctx, s.id, []uuid.UUID{alice.ID, bob.ID}); err != nil {
+					t.Fatalf("add members: %v", err)
+				}
+				if !s.pinned {
+					continue
+				}
+				member, err := store.Members().Get(ctx, s.id, alice.ID)
+				if err != nil {
+					t.Fatalf("get member: %v", err)
+				}
+				member.IsPinned = true
+				if err := store.Members().Update(ctx, member); err != nil {
+					t.Fatalf("pin conversation: %v", err)
+				}
+			}
+			// Conversations alice isn't in stay out of her pages
+			if err := store.Conversations().Create(ctx, &models.Conversation{ID: bobsConversation, LastMessageAt: base}); err != nil {
+				t.Fatalf("create conversation: %v", err)
+			}
+			if err := store.Members().Add(ctx, bobsConversation, []uuid.UUID{bob.ID}); err != nil {
+				t.Fatalf("add members: %v", err)
+			}
+
+			// Walk the pages the way a client does, passing back the encoded cursor
+			var listed []listedConversation
+			var cursor *models.ConversationCursor
+			for pages := 0; ; pages++ {
+				if pages > len(seeds) {
+					t.Fatalf("pagination did not end")
+				}
+				page, err := store.Conversations().ListForUser(ctx, alice.ID, enums.ConversationsAll, cursor, 3)
+				if err != nil {
+					t.Fatalf("list conversations: %v", err)
+				}
+				for _, c := range page.Conversations {
+					listed = append(listed, listedConversation{ID: c.Conversation.ID, IsPinned: c.Settings.IsPinned})
+				}
+
+				if !page.HasMore {
+					if page.NextCursor != "" {
+						t.Fatalf("got next cursor %q on the final page", page.NextCursor)
+					}
+					if len(page.Conversations) != len(seeds)%3 {
+						t.Fatalf("got %d conversations on the final page, want %d", len(page.Conversations), len(seeds)%3)
+					}
+					break
+				}
+				if len(page.Conversations) != 3 {
+					t.Fatalf("got %d conversations on a full page, want 3", len(page.Conversations))
+				}
+				cursor, err = models.DecodeConversationCursor(page.NextCursor)
+				if err != nil {
+					t.Fatalf("decode cursor %q: %v", page.NextCursor, err)
+				}
+			}
+
+			if len(listed) != len(expected) {
+				t.Fatalf("got %d conversations, want %d", len(listed), len(expected))
+			}
+			for i := range expected {
+				if listed[i] != expected[i] {
+					t.Fatalf("conversation %d: got %+v, want %+v", i, listed[i], expected[i])
+				}
+			}
+		})
+	}
+}
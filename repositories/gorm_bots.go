@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormBots struct {
+	db *gorm.DB
+}
+
+type gormApiTokens struct {
+	db *gorm.DB
+}
+
+func (r *gormBots) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Bot, error) {
+	var bot models.Bot
+	err := r.db.WithContext(ctx).
+		InnerJoins("User").
+		Where("bots.user_id = ?", userID).
+		First(&bot).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &bot, nil
+}
+
+func (r *gormBots) List(ctx context.Context) ([]models.Bot, error) {
+	var bots []models.Bot
+	if err := r.db.WithContext(ctx).InnerJoins("User").Order("bots.created_at").Find(&bots).Error; err != nil {
+		return nil, err
+	}
+	return bots, nil
+}
+
+func (r *gormBots) ListForCommand(ctx context.Context, conversationID uuid.UUID, command string) ([]models.Bot, error) {
+	var candidates []models.Bot
+	err := r.db.WithContext(ctx).
+		InnerJoins("User").
+		Joins("JOIN conversation_members ON conversation_members.member_id = bots.user_id AND conversation_members.deleted_at IS NULL").
+		Where("conversation_members.conversation_id = ? AND bots.webhook_url <> ''", conversationID).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var bots []models.Bot
+	for _, bot := range candidates {
+		if bot.HandlesCommand(command) {
+			bots = append(bots, bot)
+		}
+	}
+	return bots, nil
+}
+
+func (r *gormBots) Create(ctx context.Context, bot *models.Bot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := (&gormUsers{db: tx}).Create(ctx, &bot.User); err != nil {
+			return err
+		}
+		bot.UserID = bot.User.ID
+		return tx.Omit("User").Create(bot).Error
+	})
+}
+
+func (r *gormBots) Update(ctx context.Context, bot *models.Bot) error {
+	return r.db.WithContext(ctx).Omit("User").Save(bot).Error
+}
+
+func (r *gormBots) Delete(ctx context.Context, bot *models.Bot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ApiToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", bot.UserID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, "id = ?", bot.UserID).Error
+	})
+}
+
+func (r *gormApiTokens) Create(ctx context.Context, token *models.ApiToken) error {
+	token.ID = uuid.New()
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *gormApiTokens) GetByHash(ctx context.Context, hash string) (*models.ApiToken, error) {
+	var token models.ApiToken
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", hash).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r *gormApiTokens) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.ApiToken, error) {
+	var tokens []models.ApiToken
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *gormApiTokens) Revoke(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ApiToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormApiTokens) Touch(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.ApiToken{}).Where("id = ?", id).UpdateColumn("last_used_at", time.Now()).Error
+}
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormConversations struct {
+	db *gorm.DB
+}
+
+// conversationWithSettings is a conversation row joined with the requesting member's settings
+type conversationWithSettings struct {
+	models.Conversation `gorm:"embedded"`
+	IsPinned            bool
+	IsArchived          bool
+	MutedUntil          *time.Time
+}
+
+func (r *gormConversations) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	if err := r.db.WithContext(ctx).First(&conversation, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &conversation, nil
+}
+
+func (r *gormConversations) GetWithMembers(ctx context.Context, id uuid.UUID) (*models.ConversationWithMembers, error) {
+	conversation, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	membersByConversation, err := r.membersByConversation(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ConversationWithMembers{
+		Conversation: conversation,
+		Members:      membersByConversation[id],
+	}, nil
+}
+
+func (r *gormConversations) ListForUser(ctx context.Context, userID uuid.UUID, filter enums.ConversationFilter, cursor *models.ConversationCursor, limit int) (models.PaginatedConversations, error) {
+	var rows []conversationWithSettings
+
+	query := r.db.WithContext(ctx).
+		Table("conversations").
+		Select("conversations.*, conversation_members.is_pinned, conversation_members.is_archived, conversation_members.muted_until").
+		Joins("JOIN conversation_members ON conversations.id = conversation_members.conversation_id").
+		Where("conversations.deleted_at is null AND conversation_members.deleted_at is null").
+		Where("conversation_members.member_id = ?", userID)
+
+	switch filter {
+	case enums.ConversationsArchived:
+		query = query.Where("conversation_members.is_archived = ?", true)
+	case enums.ConversationsActive:
+		query = query.Where("conversation_members.is_archived = ?", false)
+	}
+
+	// Continue strictly after the last row of the previous page in (is_pinned, last_message_at, id) order
+	if cursor != nil {
+		query = query.Where(
+			"conversation_members.is_pinned < ? OR "+
+				"(conversation_members.is_pinned = ? AND conversations.last_message_at < ?) OR "+
+				"(conversation_members.is_pinned = ? AND conversations.last_message_at = ? AND conversations.id < ?)",
+			cursor.IsPinned,
+			cursor.IsPinned, cursor.LastMessageAt,
+			cursor.IsPinned, cursor.LastMessageAt, cursor.ID,
+		)
+	}
+
+	// Fetch one extra row to find out whether another page follows
+	err := query.
+		Order("conversation_members.is_pinned DESC").
+		Order("conversations.last_message_at DESC").
+		Order("conversations.id DESC").
+		Limit(limit + 1).
+		Scan(&rows).Error
+	if err != nil {
+		return models.PaginatedConversations{}, err
+	}
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	conversationIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		conversationIDs = append(conversationIDs, row.ID)
+	}
+
+	membersByConversation, err := r.membersByConversation(ctx, conversationIDs)
+	if err != nil {
+		return models.PaginatedConversations{}, err
+	}
+
+	settings := make([]models.ConversationSettings, 0, len(rows))
+	conversations := make([]models.Conversation, 0, len(rows))
+	for _, row := range rows {
+		conversations = append(conversations, row.Conversation)
+		settings = append(settings, models.ConversationSettings{
+			IsPinned:   row.IsPinned,
+			IsArchived: row.IsArchived,
+			MutedUntil: row.MutedUntil,
+		})
+	}
+
+	return conversationPage(conversations, settings, membersByConversation, hasMore), nil
+}
+
+func (r *gormConversations) ListAllForUser(ctx context.Context, userID uuid.UUID) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+	err := r.db.WithContext(ctx).
+		Joins("JOIN conversation_members ON conversations.id = conversation_members.conversation_id").
+		Where("conversation_members.deleted_at is null AND conversation_members.member_id = ?", userID).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+func (r *gormConversations) Create(ctx context.Context, conversation *models.Conversation) error {
+	if conversation.ID == uuid.Nil {
+		conversation.ID = uuid.New()
+	}
+	if conversation.LastMessageAt.IsZero() {
+		conversation.LastMessageAt = time.Now()
+	}
+	return r.db.WithContext(ctx).Create(conversation).Error
+}
+
+func (r *gormConversations) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Conversation{}).Error
+}
+
+// membersByConversation loads the members of several conversations in a single query
+func (r *gormConversations) membersByConversation(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID][]*models.User, error) {
+	var rows []struct {
+		models.User    `gorm:"embedded"`
+		ConversationID uuid.UUID
+	}
+
+	membersByConversation := make(map[uuid.UUID][]*models.User, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return membersByConversation, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("users.*, conversation_members.conversation_id").
+		Joins("JOIN conversation_members ON users.id = conversation_members.member_id").
+		Where("users.deleted_at is null AND conversation_members.deleted_at is null").
+		Where("conversation_members.conversation_id IN ?", conversationIDs).
+		Order("conversation_members.created_at").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		user := rows[i].User
+		membersByConversation[rows[i].ConversationID] = append(membersByConversation[rows[i].ConversationID], &user)
+	}
+
+	return membersByConversation, nil
+}
+
+// conversationPage assembles a page of conversations in the order they were listed. hasMore
+// tells whether another page follows, in which case the cursor points at the last conversation.
+func conversationPage(conversations []models.Conversation, settings []models.ConversationSettings, membersByConversation map[uuid.UUID][]*models.User, hasMore bool) models.PaginatedConversations {
+	results := make([]models.ConversationWithMembers, 0, len(conversations))
+	for i := range conversations {
+		conversation := conversations[i]
+		members := membersByConversation[conversation.ID]
+		if members == nil {
+			members = []*models.User{}
+		}
+
+		conversationSettings := settings[i]
+		results = append(results, models.ConversationWithMembers{
+			Conversation: &conversation,
+			Members:      members,
+			Settings:     &conversationSettings,
+		})
+	}
+
+	page := models.PaginatedConversations{
+		Conversations: results,
+		HasMore:       hasMore,
+	}
+	if hasMore {
+		last := len(conversations) - 1
+		page.NextCursor = models.ConversationCursor{
+			IsPinned:      settings[last].IsPinned,
+			LastMessageAt: conversations[last].LastMessageAt,
+			ID:            conversations[last].ID,
+		}.Encode()
+	}
+
+	return page
+}
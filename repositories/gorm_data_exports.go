@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormDataExports struct {
+	db *gorm.DB
+}
+
+func (r *gormDataExports) Create(ctx context.Context, export *models.DataExport) error {
+	export.ID = uuid.New()
+	export.Status = enums.ExportPending
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+func (r *gormDataExports) Update(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Save(export).Error
+}
+
+func (r *gormDataExports) GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.WithContext(ctx).First(&export, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &export, nil
+}
+
+func (r *gormDataExports) ListByStatus(ctx context.Context, status enums.ExportStatus) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at").Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *gormDataExports) ListExpired(ctx context.Context, now time.Time) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", enums.ExportCompleted, now).
+		Find(&exports).Error
+	if err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *gormDataExports) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *gormDataExports) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ? AND status = ?", id, enums.ExportPending).
+		Update("status", enums.ExportProcessing)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *gormDataExports) ReleaseStale(ctx context.Context, claimedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("status = ? AND updated_at < ?", enums.ExportProcessing, claimedBefore).
+		Update("status", enums.ExportPending)
+	return result.RowsAffected, result.Error
+}
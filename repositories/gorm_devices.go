@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormDevices struct {
+	db *gorm.DB
+}
+
+func (r *gormDevices) Register(ctx context.Context, device *models.Device) error {
+	device.ID = uuid.New()
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "app_version", "updated_at"}),
+		}).
+		Create(device).Error
+	if err != nil {
+		return err
+	}
+
+	// Reload so an existing registration keeps its original ID. The ID generated above would
+	// otherwise be part of the query.
+	var stored models.Device
+	if err := r.db.WithContext(ctx).First(&stored, "token = ?", device.Token).Error; err != nil {
+		return err
+	}
+	*device = stored
+	return nil
+}
+
+func (r *gormDevices) ListByUsers(ctx context.Context, userIDs []uuid.UUID) ([]models.Device, error) {
+	var devices []models.Device
+	if len(userIDs) == 0 {
+		return devices, nil
+	}
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (r *gormDevices) DeleteForUser(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.Device{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormDevices) DeleteByToken(ctx context.Context, token string) error {
+	return r.db.WithContext(ctx).Where("token = ?", token).Delete(&models.Device{}).Error
+}
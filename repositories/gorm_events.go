@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormEvents struct {
+	db *gorm.DB
+}
+
+func (r *gormEvents) Add(ctx context.Context, events ...*models.OutboxEvent) error {
+	for _, event := range events {
+		if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *gormEvents) ListDue(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Order("created_at").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *gormEvents) CountUnpublished(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.OutboxEvent{}).Where("published_at IS NULL").Count(&count).Error
+	return count, err
+}
+
+func (r *gormEvents) Lease(ctx context.Context, event *models.OutboxEvent, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ? AND published_at IS NULL AND next_attempt_at = ?", event.ID, event.NextAttemptAt).
+		UpdateColumn("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	event.NextAttemptAt = until
+	return result.RowsAffected == 1, nil
+}
+
+func (r *gormEvents) Update(ctx context.Context, event *models.OutboxEvent) error {
+	return r.db.WithContext(ctx).Save(event).Error
+}
+
+func (r *gormEvents) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("published_at < ?", before).Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormEvents) IsProcessed(ctx context.Context, consumer string, eventID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.ProcessedEvent{}).
+		Where("consumer = ? AND event_id = ?", consumer, eventID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormEvents) MarkProcessed(ctx context.Context, consumer string, eventID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ProcessedEvent{Consumer: consumer, EventID: eventID, ProcessedAt: time.Now()}).Error
+}
+
+func (r *gormEvents) DeleteProcessed(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("processed_at < ?", before).Delete(&models.ProcessedEvent{})
+	return result.RowsAffected, result.Error
+}
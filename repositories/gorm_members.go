@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormMembers struct {
+	db *gorm.DB
+}
+
+func (r *gormMembers) Get(ctx context.Context, conversationID, memberID uuid.UUID) (*models.ConversationMember, error) {
+	var member models.ConversationMember
+	err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND member_id = ?", conversationID, memberID).
+		First(&member).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &member, nil
+}
+
+func (r *gormMembers) Count(ctx context.Context, conversationID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ConversationMember{}).Where("conversation_id = ?", conversationID).Count(&count).Error
+	return count, err
+}
+
+func (r *gormMembers) Add(ctx context.Context, conversationID uuid.UUID, memberIDs []uuid.UUID) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+
+	var members []models.ConversationMember
+	for _, memberID := range memberIDs {
+		members = append(members, models.ConversationMember{
+			ID:             uuid.New(),
+			ConversationID: conversationID,
+			MemberID:       memberID,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		})
+	}
+	return r.db.WithContext(ctx).Omit("Conversation", "Member").Create(&members).Error
+}
+
+func (r *gormMembers) Update(ctx context.Context, member *models.ConversationMember) error {
+	return r.db.WithContext(ctx).Omit("Conversation", "Member").Save(member).Error
+}
+
+func (r *gormMembers) Remove(ctx context.Context, conversationID, memberID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("conversation_id = ? AND member_id = ?", conversationID, memberID).
+		Delete(&models.ConversationMember{}).Error
+}
+
+func (r *gormMembers) RemoveAll(ctx context.Context, conversationID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("conversation_id = ?", conversationID).Delete(&models.ConversationMember{}).Error
+}
+
+func (r *gormMembers) ListNotifiableIDs(ctx context.Context, conversationID, excludeMemberID uuid.UUID) ([]uuid.UUID, error) {
+	var memberIDs []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&models.ConversationMember{}).
+		Where("conversation_id = ? AND member_id <> ?", conversationID, excludeMemberID).
+		Where("muted_until IS NULL OR muted_until <= ?", time.Now()).
+		Pluck("member_id", &memberIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return memberIDs, nil
+}
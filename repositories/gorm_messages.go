@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormMessages struct {
+	db *gorm.DB
+}
+
+type gormAttachments struct {
+	db *gorm.DB
+}
+
+func (r *gormMessages) Create(ctx context.Context, message *models.Message) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Omit("Conversation", "Sender").Create(message).Error
+}
+
+func (r *gormMessages) ListBySender(ctx context.Context, senderID uuid.UUID) ([]models.Message, error) {
+	var messages []models.Message
+	if err := r.db.WithContext(ctx).Where("sender_id = ?", senderID).Order("created_at").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *gormAttachments) ListByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+	if err := r.db.WithContext(ctx).Where("message_id IN ?", messageIDs).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
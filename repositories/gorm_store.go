@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GormStore keeps the data in a database through gorm
+type GormStore struct {
+	db *gorm.DB
+}
+
+var _ Store = (*GormStore)(nil)
+
+// NewGormStore creates a store using the given database
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Users() UserRepository                 { return &gormUsers{db: s.db} }
+func (s *GormStore) Conversations() ConversationRepository { return &gormConversations{db: s.db} }
+func (s *GormStore) Members() MemberRepository             { return &gormMembers{db: s.db} }
+func (s *GormStore) Messages() MessageRepository           { return &gormMessages{db: s.db} }
+func (s *GormStore) Attachments() AttachmentRepository     { return &gormAttachments{db: s.db} }
+func (s *GormStore) Events() EventRepository               { return &gormEvents{db: s.db} }
+func (s *GormStore) AuditLogs() AuditLogRepository         { return &gormAuditLogs{db: s.db} }
+func (s *GormStore) Devices() DeviceRepository             { return &gormDevices{db: s.db} }
+func (s *GormStore) DataExports() DataExportRepository     { return &gormDataExports{db: s.db} }
+func (s *GormStore) Bots() BotRepository                   { return &gormBots{db: s.db} }
+func (s *GormStore) ApiTokens() ApiTokenRepository         { return &gormApiTokens{db: s.db} }
+func (s *GormStore) WebhookSubscriptions() WebhookSubscriptionRepository {
+	return &gormWebhookSubscriptions{db: s.db}
+}
+func (s *GormStore) WebhookDeliveries() WebhookDeliveryRepository {
+	return &gormWebhookDeliveries{db: s.db}
+}
+
+func (s *GormStore) Transaction(ctx context.Context, fn func(store Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx})
+	})
+}
+
+// notFound translates gorm's not found error into ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r *gormUsers) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUsers) GetByEmailOrUsername(ctx context.Context, email, username string) (*models.User, error) {
+	var user models.User
+
+	if email != "" {
+		if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err == nil {
+			return &user, nil
+		}
+	}
+
+	if username != "" {
+		if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err == nil {
+			return &user, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (r *gormUsers) Filter(ctx context.Context, filter models.UserFilter, page, limit int) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.IsStaff != nil {
+		query = query.Where("is_staff = ?", *filter.IsStaff)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *gormUsers) CountExisting(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	var count int64
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("id IN ?", ids).Count(&count).Error
+	return count, err
+}
+
+func (r *gormUsers) Create(ctx context.Context, user *models.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.Type == "" {
+		user.Type = enums.UserTypeHuman
+	}
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *gormUsers) Update(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+func (r *gormUsers) TouchLastSeen(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).UpdateColumn("last_seen", time.Now()).Error
+}
+
+func (r *gormUsers) ListDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).Where("deletion_scheduled_at <= ?", now).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *gormUsers) HardDelete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		messageIDs := tx.Unscoped().Model(&models.Message{}).Select("id").Where("sender_id = ?", id)
+
+		if err := tx.Unscoped().Where("message_id IN (?)", messageIDs).Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("sender_id = ?", id).Delete(&models.Message{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("member_id = ?", id).Delete(&models.ConversationMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&models.DataExport{}).Error; err != nil {
+			return err
+		}
+		if err := deleteCredentials(tx, id); err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", id).Delete(&models.User{}).Error
+	})
+}
+
+func (r *gormUsers) Anonymise(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		directConversationIDs := tx.Unscoped().Model(&models.Conversation{}).Select("id").Where("is_group = ?", false)
+		directMessageIDs := tx.Unscoped().Model(&models.Message{}).Select("id").
+			Where("sender_id = ? AND conversation_id IN (?)", id, directConversationIDs)
+
+		if err := tx.Unscoped().Where("message_id IN (?)", directMessageIDs).Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().
+			Where("sender_id = ? AND conversation_id IN (?)", id, directConversationIDs).
+			Delete(&models.Message{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("member_id = ?", id).Delete(&models.ConversationMember{}).Error; err != nil {
+			return err
+		}
+		if err := deleteCredentials(tx, id); err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.User{}).Where("id = ?", id).Updates(anonymisedUserFields(id)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&models.User{}).Error
+	})
+}
+
+func (r *gormUsers) ListOfflineIDs(ctx context.Context, ids []uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	var offline []uuid.UUID
+	if len(ids) == 0 {
+		return offline, nil
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id IN ?", ids).
+		Where("last_seen IS NULL OR last_seen < ?", since).
+		Pluck("id", &offline).Error
+	if err != nil {
+		return nil, err
+	}
+	return offline, nil
+}
+
+func (r *gormUsers) Locales(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	locales := make(map[uuid.UUID]string)
+	if len(ids) == 0 {
+		return locales, nil
+	}
+
+	var rows []struct {
+		ID     uuid.UUID
+		Locale string
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Select("id", "locale").
+		Where("id IN ?", ids).
+		Where("locale IS NOT NULL AND locale <> ''").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		locales[row.ID] = row.Locale
+	}
+	return locales, nil
+}
+
+// deleteCredentials removes the push devices, API tokens and bot settings of a user, so nothing can
+// reach or act as the account once it is gone
+func deleteCredentials(tx *gorm.DB, id uuid.UUID) error {
+	if err := tx.Where("user_id = ?", id).Delete(&models.Device{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", id).Delete(&models.ApiToken{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", id).Delete(&models.Bot{}).Error
+}
+
+// anonymisedUserFields are the values that replace the personal data of a deleted account
+func anonymisedUserFields(id uuid.UUID) map[string]interface{} {
+	placeholder := fmt.Sprintf("deleted%s", strings.ReplaceAll(id.String(), "-", ""))
+	return map[string]interface{}{
+		"username":              placeholder,
+		"email":                 placeholder + "@deleted.invalid",
+		"password":              "",
+		"first_name":            "Deleted",
+		"last_name":             "User",
+		"date_of_birth":         time.Time{},
+		"gender":                "",
+		"mobile_number":         "",
+		"profile_photo_path":    "",
+		"profile_photo_url":     "",
+		"status":                enums.UserInactive,
+		"status_reason":         "account deleted",
+		"deletion_scheduled_at": nil,
+	}
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormWebhookSubscriptions struct {
+	db *gorm.DB
+}
+
+type gormWebhookDeliveries struct {
+	db *gorm.DB
+}
+
+func (r *gormWebhookSubscriptions) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	subscription.ID = uuid.New()
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *gormWebhookSubscriptions) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Save(subscription).Error
+}
+
+func (r *gormWebhookSubscriptions) Delete(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Delete(subscription).Error
+}
+
+func (r *gormWebhookSubscriptions) GetByID(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := r.db.WithContext(ctx).First(&subscription, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &subscription, nil
+}
+
+func (r *gormWebhookSubscriptions) List(ctx context.Context, activeOnly bool) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	query := r.db.WithContext(ctx).Order("created_at")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *gormWebhookDeliveries) Create(ctx context.Context, deliveries ...models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&deliveries).Error
+}
+
+func (r *gormWebhookDeliveries) Update(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Save(delivery).Error
+}
+
+func (r *gormWebhookDeliveries) GetByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.WithContext(ctx).First(&delivery, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &delivery, nil
+}
+
+func (r *gormWebhookDeliveries) ListDue(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", enums.DeliveryPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *gormWebhookDeliveries) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("status = ?", enums.DeliveryPending).Count(&count).Error
+	return count, err
+}
+
+func (r *gormWebhookDeliveries) Lease(ctx context.Context, delivery *models.WebhookDelivery, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, enums.DeliveryPending, delivery.NextAttemptAt).
+		UpdateColumn("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	delivery.NextAttemptAt = until
+	return result.RowsAffected == 1, nil
+}
+
+func (r *gormWebhookDeliveries) Filter(ctx context.Context, subscriptionID uuid.UUID, status enums.WebhookDeliveryStatus, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryAuditLogs struct {
+	s *MemoryStore
+}
+
+func (r *memoryAuditLogs) Create(ctx context.Context, entry *models.AuditLog) error {
+	return r.s.do(func(d *memoryData) error {
+		entry.ID = uuid.New()
+		entry.CreatedAt = time.Now()
+		d.auditLogs = append(d.auditLogs, *entry)
+		return nil
+	})
+}
+
+func (r *memoryAuditLogs) Filter(ctx context.Context, filter models.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	r.s.do(func(d *memoryData) error {
+		// Entries are appended in order, walk them backwards for newest first
+		for i := len(d.auditLogs) - 1; i >= 0; i-- {
+			entry := d.auditLogs[i]
+			if filter.ActorID != nil && (entry.ActorID == nil || *entry.ActorID != *filter.ActorID) {
+				continue
+			}
+			if filter.TargetType != "" && entry.TargetType != filter.TargetType {
+				continue
+			}
+			if filter.TargetID != "" && entry.TargetID != filter.TargetID {
+				continue
+			}
+			if filter.Action != "" && entry.Action != filter.Action {
+				continue
+			}
+			if !filter.From.IsZero() && entry.CreatedAt.Before(filter.From) {
+				continue
+			}
+			if !filter.To.IsZero() && entry.CreatedAt.After(filter.To) {
+				continue
+			}
+			logs = append(logs, entry)
+		}
+		return nil
+	})
+	return paginate(logs, page, limit), int64(len(logs)), nil
+}
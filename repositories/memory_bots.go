@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryBots struct {
+	s *MemoryStore
+}
+
+type memoryApiTokens struct {
+	s *MemoryStore
+}
+
+func (r *memoryBots) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Bot, error) {
+	var bot models.Bot
+	err := r.s.do(func(d *memoryData) error {
+		found, ok := botWithUser(d, userID)
+		if !ok {
+			return ErrNotFound
+		}
+		bot = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+func (r *memoryBots) List(ctx context.Context) ([]models.Bot, error) {
+	var bots []models.Bot
+	r.s.do(func(d *memoryData) error {
+		for userID := range d.bots {
+			if bot, ok := botWithUser(d, userID); ok {
+				bots = append(bots, bot)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(bots, func(i, j int) bool {
+		return bots[i].CreatedAt.Before(bots[j].CreatedAt)
+	})
+	return bots, nil
+}
+
+func (r *memoryBots) ListForCommand(ctx context.Context, conversationID uuid.UUID, command string) ([]models.Bot, error) {
+	var bots []models.Bot
+	r.s.do(func(d *memoryData) error {
+		for _, member := range d.members {
+			if member.ConversationID != conversationID {
+				continue
+			}
+			bot, ok := botWithUser(d, member.MemberID)
+			if ok && bot.WebhookURL != "" && bot.HandlesCommand(command) {
+				bots = append(bots, bot)
+			}
+		}
+		return nil
+	})
+	return bots, nil
+}
+
+func (r *memoryBots) Create(ctx context.Context, bot *models.Bot) error {
+	return r.s.do(func(d *memoryData) error {
+		if err := insertUser(d, &bot.User); err != nil {
+			return err
+		}
+
+		bot.UserID = bot.User.ID
+		bot.CreatedAt = time.Now()
+		bot.UpdatedAt = bot.CreatedAt
+		stored := *bot
+		stored.User = models.User{}
+		d.bots[bot.UserID] = stored
+		return nil
+	})
+}
+
+func (r *memoryBots) Update(ctx context.Context, bot *models.Bot) error {
+	return r.s.do(func(d *memoryData) error {
+		if _, ok := d.bots[bot.UserID]; !ok {
+			return ErrNotFound
+		}
+		bot.UpdatedAt = time.Now()
+		stored := *bot
+		stored.User = models.User{}
+		d.bots[bot.UserID] = stored
+		return nil
+	})
+}
+
+func (r *memoryBots) Delete(ctx context.Context, bot *models.Bot) error {
+	return r.s.do(func(d *memoryData) error {
+		now := time.Now()
+		for id, token := range d.apiTokens {
+			if token.UserID == bot.UserID && token.RevokedAt == nil {
+				token.RevokedAt = &now
+				d.apiTokens[id] = token
+			}
+		}
+		delete(d.users, bot.UserID)
+		return nil
+	})
+}
+
+// botWithUser returns the bot together with its user account, bots whose account was deleted are left out
+func botWithUser(d *memoryData, userID uuid.UUID) (models.Bot, bool) {
+	bot, ok := d.bots[userID]
+	if !ok {
+		return models.Bot{}, false
+	}
+	user, ok := d.users[userID]
+	if !ok {
+		return models.Bot{}, false
+	}
+	bot.User = user
+	return bot, true
+}
+
+func (r *memoryApiTokens) Create(ctx context.Context, token *models.ApiToken) error {
+	return r.s.do(func(d *memoryData) error {
+		token.ID = uuid.New()
+		token.CreatedAt = time.Now()
+		d.apiTokens[token.ID] = *token
+		return nil
+	})
+}
+
+func (r *memoryApiTokens) GetByHash(ctx context.Context, hash string) (*models.ApiToken, error) {
+	var token *models.ApiToken
+	r.s.do(func(d *memoryData) error {
+		for _, candidate := range d.apiTokens {
+			if candidate.TokenHash == hash {
+				token = &candidate
+				return nil
+			}
+		}
+		return nil
+	})
+	if token == nil {
+		return nil, ErrNotFound
+	}
+	return token, nil
+}
+
+func (r *memoryApiTokens) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.ApiToken, error) {
+	tokens := []models.ApiToken{}
+	r.s.do(func(d *memoryData) error {
+		for _, token := range d.apiTokens {
+			if token.UserID == userID {
+				tokens = append(tokens, token)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+func (r *memoryApiTokens) Revoke(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	revoked := false
+	r.s.do(func(d *memoryData) error {
+		token, ok := d.apiTokens[id]
+		if !ok || token.UserID != userID || token.RevokedAt != nil {
+			return nil
+		}
+		now := time.Now()
+		token.RevokedAt = &now
+		d.apiTokens[id] = token
+		revoked = true
+		return nil
+	})
+	return revoked, nil
+}
+
+func (r *memoryApiTokens) Touch(ctx context.Context, id uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		token, ok := d.apiTokens[id]
+		if !ok {
+			return nil
+		}
+		now := time.Now()
+		token.LastUsedAt = &now
+		d.apiTokens[id] = token
+		return nil
+	})
+}
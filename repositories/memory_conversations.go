@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryConversations struct {
+	s *MemoryStore
+}
+
+func (r *memoryConversations) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.s.do(func(d *memoryData) error {
+		found, ok := d.conversations[id]
+		if !ok {
+			return ErrNotFound
+		}
+		conversation = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (r *memoryConversations) GetWithMembers(ctx context.Context, id uuid.UUID) (*models.ConversationWithMembers, error) {
+	var result *models.ConversationWithMembers
+	err := r.s.do(func(d *memoryData) error {
+		conversation, ok := d.conversations[id]
+		if !ok {
+			return ErrNotFound
+		}
+		result = &models.ConversationWithMembers{
+			Conversation: &conversation,
+			Members:      d.membersByConversation([]uuid.UUID{id})[id],
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *memoryConversations) ListForUser(ctx context.Context, userID uuid.UUID, filter enums.ConversationFilter, cursor *models.ConversationCursor, limit int) (models.PaginatedConversations, error) {
+	var page models.PaginatedConversations
+	r.s.do(func(d *memoryData) error {
+		var conversations []models.Conversation
+		var settings []models.ConversationSettings
+		for _, member := range d.members {
+			if member.MemberID != userID {
+				continue
+			}
+			conversation, ok := d.conversations[member.ConversationID]
+			if !ok {
+				continue
+			}
+			if filter == enums.ConversationsArchived && !member.IsArchived ||
+				filter == enums.ConversationsActive && member.IsArchived {
+				continue
+			}
+
+			conversations = append(conversations, conversation)
+			settings = append(settings, models.ConversationSettings{
+				IsPinned:   member.IsPinned,
+				IsArchived: member.IsArchived,
+				MutedUntil: member.MutedUntil,
+			})
+		}
+
+		// Order by (is_pinned, last_message_at, id) descending like the database does
+		positions := make([]models.ConversationCursor, len(conversations))
+		order := make([]int, len(conversations))
+		for i := range conversations {
+			positions[i] = models.ConversationCursor{
+				IsPinned:      settings[i].IsPinned,
+				LastMessageAt: conversations[i].LastMessageAt,
+				ID:            conversations[i].ID,
+			}
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool {
+			return conversationBefore(positions[order[a]], positions[order[b]])
+		})
+
+		var pageConversations []models.Conversation
+		var pageSettings []models.ConversationSettings
+		var ids []uuid.UUID
+		for _, i := range order {
+			// Continue strictly after the last row of the previous page
+			if cursor != nil && !conversationBefore(*cursor, positions[i]) {
+				continue
+			}
+			pageConversations = append(pageConversations, conversations[i])
+			pageSettings = append(pageSettings, settings[i])
+			ids = append(ids, conversations[i].ID)
+		}
+
+		hasMore := len(pageConversations) > limit
+		if hasMore {
+			pageConversations = pageConversations[:limit]
+			pageSettings = pageSettings[:limit]
+			ids = ids[:limit]
+		}
+
+		page = conversationPage(pageConversations, pageSettings, d.membersByConversation(ids), hasMore)
+		return nil
+	})
+	return page, nil
+}
+
+func (r *memoryConversations) ListAllForUser(ctx context.Context, userID uuid.UUID) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+	r.s.do(func(d *memoryData) error {
+		for _, member := range d.members {
+			if conversation, ok := d.conversations[member.ConversationID]; ok && member.MemberID == userID {
+				conversations = append(conversations, conversation)
+			}
+		}
+		return nil
+	})
+	return conversations, nil
+}
+
+func (r *memoryConversations) Create(ctx context.Context, conversation *models.Conversation) error {
+	return r.s.do(func(d *memoryData) error {
+		if conversation.ID == uuid.Nil {
+			conversation.ID = uuid.New()
+		}
+		now := time.Now()
+		if conversation.LastMessageAt.IsZero() {
+			conversation.LastMessageAt = now
+		}
+		if conversation.CreatedAt.IsZero() {
+			conversation.CreatedAt = now
+		}
+		conversation.UpdatedAt = now
+
+		d.conversations[conversation.ID] = *conversation
+		return nil
+	})
+}
+
+func (r *memoryConversations) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		delete(d.conversations, id)
+		return nil
+	})
+}
+
+// conversationBefore reports whether a comes before b when listing conversations
+func conversationBefore(a, b models.ConversationCursor) bool {
+	if a.IsPinned != b.IsPinned {
+		return a.IsPinned
+	}
+	if !a.LastMessageAt.Equal(b.LastMessageAt) {
+		return a.LastMessageAt.After(b.LastMessageAt)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) > 0
+}
+
+// membersByConversation collects the members of the given conversations in the order they joined
+func (d *memoryData) membersByConversation(conversationIDs []uuid.UUID) map[uuid.UUID][]*models.User {
+	wanted := make(map[uuid.UUID]bool, len(conversationIDs))
+	for _, id := range conversationIDs {
+		wanted[id] = true
+	}
+
+	var members []models.ConversationMember
+	for _, member := range d.members {
+		if _, ok := d.users[member.MemberID]; ok && wanted[member.ConversationID] {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+
+	membersByConversation := make(map[uuid.UUID][]*models.User, len(conversationIDs))
+	for _, member := range members {
+		user := d.users[member.MemberID]
+		membersByConversation[member.ConversationID] = append(membersByConversation[member.ConversationID], &user)
+	}
+	return membersByConversation
+}
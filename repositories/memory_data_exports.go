@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryDataExports struct {
+	s *MemoryStore
+}
+
+func (r *memoryDataExports) Create(ctx context.Context, export *models.DataExport) error {
+	return r.s.do(func(d *memoryData) error {
+		export.ID = uuid.New()
+		export.Status = enums.ExportPending
+		export.CreatedAt = time.Now()
+		export.UpdatedAt = export.CreatedAt
+		d.dataExports[export.ID] = *export
+		return nil
+	})
+}
+
+func (r *memoryDataExports) Update(ctx context.Context, export *models.DataExport) error {
+	return r.s.do(func(d *memoryData) error {
+		export.UpdatedAt = time.Now()
+		d.dataExports[export.ID] = *export
+		return nil
+	})
+}
+
+func (r *memoryDataExports) GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.s.do(func(d *memoryData) error {
+		found, ok := d.dataExports[id]
+		if !ok {
+			return ErrNotFound
+		}
+		export = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *memoryDataExports) ListByStatus(ctx context.Context, status enums.ExportStatus) ([]models.DataExport, error) {
+	exports := r.list(func(export models.DataExport) bool {
+		return export.Status == status
+	})
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].CreatedAt.Before(exports[j].CreatedAt)
+	})
+	return exports, nil
+}
+
+func (r *memoryDataExports) ListExpired(ctx context.Context, now time.Time) ([]models.DataExport, error) {
+	return r.list(func(export models.DataExport) bool {
+		return export.Status == enums.ExportCompleted && export.ExpiresAt != nil && export.ExpiresAt.Before(now)
+	}), nil
+}
+
+func (r *memoryDataExports) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.DataExport, error) {
+	return r.list(func(export models.DataExport) bool {
+		return export.UserID == userID
+	}), nil
+}
+
+func (r *memoryDataExports) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	claimed := false
+	r.s.do(func(d *memoryData) error {
+		if export, ok := d.dataExports[id]; ok && export.Status == enums.ExportPending {
+			export.Status = enums.ExportProcessing
+			export.UpdatedAt = time.Now()
+			d.dataExports[id] = export
+			claimed = true
+		}
+		return nil
+	})
+	return claimed, nil
+}
+
+func (r *memoryDataExports) ReleaseStale(ctx context.Context, claimedBefore time.Time) (int64, error) {
+	var released int64
+	r.s.do(func(d *memoryData) error {
+		for id, export := range d.dataExports {
+			if export.Status == enums.ExportProcessing && export.UpdatedAt.Before(claimedBefore) {
+				export.Status = enums.ExportPending
+				export.UpdatedAt = time.Now()
+				d.dataExports[id] = export
+				released++
+			}
+		}
+		return nil
+	})
+	return released, nil
+}
+
+// list returns the exports matching the given condition
+func (r *memoryDataExports) list(matches func(export models.DataExport) bool) []models.DataExport {
+	var exports []models.DataExport
+	r.s.do(func(d *memoryData) error {
+		for _, export := range d.dataExports {
+			if matches(export) {
+				exports = append(exports, export)
+			}
+		}
+		return nil
+	})
+	return exports
+}
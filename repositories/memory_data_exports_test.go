@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestReleaseStaleExports(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	userID := uuid.New()
+
+	claimed := &models.DataExport{UserID: userID}
+	pending := &models.DataExport{UserID: userID}
+	for _, export := range []*models.DataExport{claimed, pending} {
+		if err := store.DataExports().Create(ctx, export); err != nil {
+			t.Fatalf("create export: %v", err)
+		}
+	}
+	if ok, err := store.DataExports().Claim(ctx, claimed.ID); err != nil || !ok {
+		t.Fatalf("claim: %v, %v", ok, err)
+	}
+
+	// A claim made after the cutoff is still being worked on
+	released, err := store.DataExports().ReleaseStale(ctx, time.Now().Add(-time.Minute))
+	if err != nil || released != 0 {
+		t.Fatalf("got %d released (%v), want none", released, err)
+	}
+
+	released, err = store.DataExports().ReleaseStale(ctx, time.Now().Add(time.Minute))
+	if err != nil || released != 1 {
+		t.Fatalf("got %d released (%v), want the claimed export", released, err)
+	}
+	export, err := store.DataExports().GetByID(ctx, claimed.ID)
+	if err != nil || export.Status != enums.ExportPending {
+		t.Fatalf("got %v (%v), want the export pending again", export, err)
+	}
+	if ok, _ := store.DataExports().Claim(ctx, claimed.ID); !ok {
+		t.Fatalf("released export could not be claimed again")
+	}
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryDevices struct {
+	s *MemoryStore
+}
+
+func (r *memoryDevices) Register(ctx context.Context, device *models.Device) error {
+	return r.s.do(func(d *memoryData) error {
+		now := time.Now()
+		for _, existing := range d.devices {
+			if existing.Token == device.Token {
+				existing.UserID = device.UserID
+				existing.Platform = device.Platform
+				existing.AppVersion = device.AppVersion
+				existing.UpdatedAt = now
+				d.devices[existing.ID] = existing
+				*device = existing
+				return nil
+			}
+		}
+
+		device.ID = uuid.New()
+		device.CreatedAt = now
+		device.UpdatedAt = now
+		d.devices[device.ID] = *device
+		return nil
+	})
+}
+
+func (r *memoryDevices) ListByUsers(ctx context.Context, userIDs []uuid.UUID) ([]models.Device, error) {
+	var devices []models.Device
+	r.s.do(func(d *memoryData) error {
+		wanted := make(map[uuid.UUID]bool, len(userIDs))
+		for _, id := range userIDs {
+			wanted[id] = true
+		}
+		for _, device := range d.devices {
+			if wanted[device.UserID] {
+				devices = append(devices, device)
+			}
+		}
+		return nil
+	})
+	return devices, nil
+}
+
+func (r *memoryDevices) DeleteForUser(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	deleted := false
+	r.s.do(func(d *memoryData) error {
+		if device, ok := d.devices[id]; ok && device.UserID == userID {
+			delete(d.devices, id)
+			deleted = true
+		}
+		return nil
+	})
+	return deleted, nil
+}
+
+func (r *memoryDevices) DeleteByToken(ctx context.Context, token string) error {
+	return r.s.do(func(d *memoryData) error {
+		for id, device := range d.devices {
+			if device.Token == token {
+				delete(d.devices, id)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryEvents struct {
+	s *MemoryStore
+}
+
+func (r *memoryEvents) Add(ctx context.Context, events ...*models.OutboxEvent) error {
+	return r.s.do(func(d *memoryData) error {
+		for _, event := range events {
+			d.events = append(d.events, *event)
+		}
+		return nil
+	})
+}
+
+func (r *memoryEvents) ListDue(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	r.s.do(func(d *memoryData) error {
+		for _, event := range d.events {
+			if event.PublishedAt == nil && !event.NextAttemptAt.After(now) {
+				events = append(events, event)
+			}
+		}
+		return nil
+	})
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r *memoryEvents) CountUnpublished(ctx context.Context) (int64, error) {
+	var count int64
+	r.s.do(func(d *memoryData) error {
+		for _, event := range d.events {
+			if event.PublishedAt == nil {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, nil
+}
+
+func (r *memoryEvents) Lease(ctx context.Context, event *models.OutboxEvent, until time.Time) (bool, error) {
+	leased := false
+	r.s.do(func(d *memoryData) error {
+		for i, stored := range d.events {
+			if stored.ID == event.ID && stored.PublishedAt == nil && stored.NextAttemptAt.Equal(event.NextAttemptAt) {
+				d.events[i].NextAttemptAt = until
+				leased = true
+			}
+		}
+		return nil
+	})
+	event.NextAttemptAt = until
+	return leased, nil
+}
+
+func (r *memoryEvents) Update(ctx context.Context, event *models.OutboxEvent) error {
+	return r.s.do(func(d *memoryData) error {
+		for i, stored := range d.events {
+			if stored.ID == event.ID {
+				d.events[i] = *event
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+func (r *memoryEvents) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	r.s.do(func(d *memoryData) error {
+		kept := d.events[:0]
+		for _, event := range d.events {
+			if event.PublishedAt != nil && event.PublishedAt.Before(before) {
+				deleted++
+				continue
+			}
+			kept = append(kept, event)
+		}
+		d.events = kept
+		return nil
+	})
+	return deleted, nil
+}
+
+func (r *memoryEvents) IsProcessed(ctx context.Context, consumer string, eventID uuid.UUID) (bool, error) {
+	processed := false
+	r.s.do(func(d *memoryData) error {
+		_, processed = d.processedEvents[consumer+eventID.String()]
+		return nil
+	})
+	return processed, nil
+}
+
+func (r *memoryEvents) MarkProcessed(ctx context.Context, consumer string, eventID uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		key := consumer + eventID.String()
+		if _, ok := d.processedEvents[key]; !ok {
+			d.processedEvents[key] = models.ProcessedEvent{Consumer: consumer, EventID: eventID, ProcessedAt: time.Now()}
+		}
+		return nil
+	})
+}
+
+func (r *memoryEvents) DeleteProcessed(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	r.s.do(func(d *memoryData) error {
+		for key, processed := range d.processedEvents {
+			if processed.ProcessedAt.Before(before) {
+				delete(d.processedEvents, key)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, nil
+}
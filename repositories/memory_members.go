@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryMembers struct {
+	s *MemoryStore
+}
+
+func (r *memoryMembers) Get(ctx context.Context, conversationID, memberID uuid.UUID) (*models.ConversationMember, error) {
+	var member *models.ConversationMember
+	r.s.do(func(d *memoryData) error {
+		for _, candidate := range d.members {
+			if candidate.ConversationID == conversationID && candidate.MemberID == memberID {
+				member = &candidate
+				return nil
+			}
+		}
+		return nil
+	})
+	if member == nil {
+		return nil, ErrNotFound
+	}
+	return member, nil
+}
+
+func (r *memoryMembers) Count(ctx context.Context, conversationID uuid.UUID) (int64, error) {
+	var count int64
+	r.s.do(func(d *memoryData) error {
+		for _, member := range d.members {
+			if member.ConversationID == conversationID {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, nil
+}
+
+func (r *memoryMembers) Add(ctx context.Context, conversationID uuid.UUID, memberIDs []uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		for _, memberID := range memberIDs {
+			member := models.ConversationMember{
+				ID:             uuid.New(),
+				ConversationID: conversationID,
+				MemberID:       memberID,
+				CreatedAt:      time.Now(),
+				UpdatedAt:      time.Now(),
+			}
+			d.members[member.ID] = member
+		}
+		return nil
+	})
+}
+
+func (r *memoryMembers) Update(ctx context.Context, member *models.ConversationMember) error {
+	return r.s.do(func(d *memoryData) error {
+		if _, ok := d.members[member.ID]; !ok {
+			return ErrNotFound
+		}
+		member.UpdatedAt = time.Now()
+		d.members[member.ID] = *member
+		return nil
+	})
+}
+
+func (r *memoryMembers) Remove(ctx context.Context, conversationID, memberID uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		for id, member := range d.members {
+			if member.ConversationID == conversationID && member.MemberID == memberID {
+				delete(d.members, id)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *memoryMembers) RemoveAll(ctx context.Context, conversationID uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		for id, member := range d.members {
+			if member.ConversationID == conversationID {
+				delete(d.members, id)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *memoryMembers) ListNotifiableIDs(ctx context.Context, conversationID, excludeMemberID uuid.UUID) ([]uuid.UUID, error) {
+	var memberIDs []uuid.UUID
+	r.s.do(func(d *memoryData) error {
+		now := time.Now()
+		for _, member := range d.members {
+			if member.ConversationID == conversationID && member.MemberID != excludeMemberID && !member.IsMuted(now) {
+				memberIDs = append(memberIDs, member.MemberID)
+			}
+		}
+		return nil
+	})
+	return memberIDs, nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryMessages struct {
+	s *MemoryStore
+}
+
+type memoryAttachments struct {
+	s *MemoryStore
+}
+
+func (r *memoryMessages) Create(ctx context.Context, message *models.Message) error {
+	return r.s.do(func(d *memoryData) error {
+		if message.ID == uuid.Nil {
+			message.ID = uuid.New()
+		}
+		if message.CreatedAt.IsZero() {
+			message.CreatedAt = time.Now()
+		}
+		message.UpdatedAt = message.CreatedAt
+		d.messages[message.ID] = *message
+
+		// Keep the conversation's last activity time in step like Message.AfterCreate does
+		if conversation, ok := d.conversations[message.ConversationID]; ok && conversation.LastMessageAt.Before(message.CreatedAt) {
+			conversation.LastMessageAt = message.CreatedAt
+			d.conversations[conversation.ID] = conversation
+		}
+		return nil
+	})
+}
+
+func (r *memoryMessages) ListBySender(ctx context.Context, senderID uuid.UUID) ([]models.Message, error) {
+	var messages []models.Message
+	r.s.do(func(d *memoryData) error {
+		for _, message := range d.messages {
+			if message.SenderID == senderID {
+				messages = append(messages, message)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	return messages, nil
+}
+
+func (r *memoryAttachments) ListByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	r.s.do(func(d *memoryData) error {
+		wanted := make(map[uuid.UUID]bool, len(messageIDs))
+		for _, id := range messageIDs {
+			wanted[id] = true
+		}
+		for _, attachment := range d.attachments {
+			if wanted[attachment.MessageID] {
+				attachments = append(attachments, attachment)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].ID < attachments[j].ID
+	})
+	return attachments, nil
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"errors"
+	"maps"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrDuplicate is returned by the memory store when a unique field is already taken
+var ErrDuplicate = errors.New("duplicate record")
+
+// MemoryStore keeps the data in memory. It is meant for tests and running the API without a database.
+// Deleted records are removed rather than soft deleted.
+type MemoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+	inTx bool
+}
+
+// memoryData holds the records of a MemoryStore
+type memoryData struct {
+	users         map[uuid.UUID]models.User
+	conversations map[uuid.UUID]models.Conversation
+	members       map[uuid.UUID]models.ConversationMember
+	messages      map[uuid.UUID]models.Message
+	attachments   map[uint]models.Attachment
+	events        []models.OutboxEvent
+	auditLogs     []models.AuditLog
+	// processedEvents is keyed by the consumer followed by the event ID
+	processedEvents      map[string]models.ProcessedEvent
+	devices              map[uuid.UUID]models.Device
+	dataExports          map[uuid.UUID]models.DataExport
+	bots                 map[uuid.UUID]models.Bot // keyed by the bot's user ID
+	apiTokens            map[uuid.UUID]models.ApiToken
+	webhookSubscriptions map[uuid.UUID]models.WebhookSubscription
+	webhookDeliveries    map[uuid.UUID]models.WebhookDelivery
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			users:         map[uuid.UUID]models.User{},
+			conversations: map[uuid.UUID]models.Conversation{},
+			members:       map[uuid.UUID]models.ConversationMember{},
+			messages:      map[uuid.UUID]models.Message{},
+			attachments:   map[uint]models.Attachment{},
+
+			processedEvents:      map[string]models.ProcessedEvent{},
+			devices:              map[uuid.UUID]models.Device{},
+			dataExports:          map[uuid.UUID]models.DataExport{},
+			bots:                 map[uuid.UUID]models.Bot{},
+			apiTokens:            map[uuid.UUID]models.ApiToken{},
+			webhookSubscriptions: map[uuid.UUID]models.WebhookSubscription{},
+			webhookDeliveries:    map[uuid.UUID]models.WebhookDelivery{},
+		},
+	}
+}
+
+func (s *MemoryStore) Users() UserRepository                 { return &memoryUsers{s: s} }
+func (s *MemoryStore) Conversations() ConversationRepository { return &memoryConversations{s: s} }
+func (s *MemoryStore) Members() MemberRepository             { return &memoryMembers{s: s} }
+func (s *MemoryStore) Messages() MessageRepository           { return &memoryMessages{s: s} }
+func (s *MemoryStore) Attachments() AttachmentRepository     { return &memoryAttachments{s: s} }
+func (s *MemoryStore) Events() EventRepository               { return &memoryEvents{s: s} }
+func (s *MemoryStore) AuditLogs() AuditLogRepository         { return &memoryAuditLogs{s: s} }
+func (s *MemoryStore) Devices() DeviceRepository             { return &memoryDevices{s: s} }
+func (s *MemoryStore) DataExports() DataExportRepository     { return &memoryDataExports{s: s} }
+func (s *MemoryStore) Bots() BotRepository                   { return &memoryBots{s: s} }
+func (s *MemoryStore) ApiTokens() ApiTokenRepository         { return &memoryApiTokens{s: s} }
+func (s *MemoryStore) WebhookSubscriptions() WebhookSubscriptionRepository {
+	return &memoryWebhookSubscriptions{s: s}
+}
+func (s *MemoryStore) WebhookDeliveries() WebhookDeliveryRepository {
+	return &memoryWebhookDeliveries{s: s}
+}
+
+// Transaction holds the store's lock while fn runs, so transactions and other operations never
+// interleave. The data is restored from a snapshot if fn fails.
+func (s *MemoryStore) Transaction(ctx context.Context, fn func(store Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	if err := fn(&MemoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = *snapshot
+		return err
+	}
+	return nil
+}
+
+// AddAttachment stores an attachment, there is no API to upload one yet
+func (s *MemoryStore) AddAttachment(attachment models.Attachment) {
+	s.do(func(d *memoryData) error {
+		if attachment.ID == 0 {
+			attachment.ID = uint(len(d.attachments) + 1)
+		}
+		d.attachments[attachment.ID] = attachment
+		return nil
+	})
+}
+
+// OutboxEvents returns the events written to the outbox so far
+func (s *MemoryStore) OutboxEvents() []models.OutboxEvent {
+	var events []models.OutboxEvent
+	s.do(func(d *memoryData) error {
+		events = append(events, d.events...)
+		return nil
+	})
+	return events
+}
+
+// do runs fn on the data, taking the lock unless a transaction already holds it
+func (s *MemoryStore) do(fn func(d *memoryData) error) error {
+	if !s.inTx {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	return fn(s.data)
+}
+
+// clone copies the data so it can be restored after a failed transaction
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		users:         maps.Clone(d.users),
+		conversations: maps.Clone(d.conversations),
+		members:       maps.Clone(d.members),
+		messages:      maps.Clone(d.messages),
+		attachments:   maps.Clone(d.attachments),
+		events:        append([]models.OutboxEvent(nil), d.events...),
+		auditLogs:     append([]models.AuditLog(nil), d.auditLogs...),
+
+		processedEvents:      maps.Clone(d.processedEvents),
+		devices:              maps.Clone(d.devices),
+		dataExports:          maps.Clone(d.dataExports),
+		bots:                 maps.Clone(d.bots),
+		apiTokens:            maps.Clone(d.apiTokens),
+		webhookSubscriptions: maps.Clone(d.webhookSubscriptions),
+		webhookDeliveries:    maps.Clone(d.webhookDeliveries),
+	}
+}
+
+// paginate returns the page of items for the given 1-based page number
+func paginate[T any](items []T, page, limit int) []T {
+	start := (page - 1) * limit
+	if start < 0 || start >= len(items) {
+		return []T{}
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
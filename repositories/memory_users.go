@@ -0,0 +1,270 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryUsers struct {
+	s *MemoryStore
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.s.do(func(d *memoryData) error {
+		found, ok := d.users[id]
+		if !ok {
+			return ErrNotFound
+		}
+		user = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *memoryUsers) GetByEmailOrUsername(ctx context.Context, email, username string) (*models.User, error) {
+	var user *models.User
+	r.s.do(func(d *memoryData) error {
+		for _, candidate := range d.users {
+			if email != "" && candidate.Email == email {
+				found := candidate
+				user = &found
+				return nil
+			}
+		}
+		for _, candidate := range d.users {
+			if username != "" && candidate.Username == username {
+				found := candidate
+				user = &found
+				return nil
+			}
+		}
+		return nil
+	})
+	if user == nil {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUsers) Filter(ctx context.Context, filter models.UserFilter, page, limit int) ([]models.User, int64, error) {
+	var users []models.User
+	r.s.do(func(d *memoryData) error {
+		for _, user := range d.users {
+			if filter.Status != "" && user.Status != filter.Status {
+				continue
+			}
+			if filter.IsStaff != nil && user.IsStaff != *filter.IsStaff {
+				continue
+			}
+			if !filter.CreatedFrom.IsZero() && user.CreatedAt.Before(filter.CreatedFrom) {
+				continue
+			}
+			if !filter.CreatedTo.IsZero() && !user.CreatedAt.Before(filter.CreatedTo) {
+				continue
+			}
+			users = append(users, user)
+		}
+		return nil
+	})
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.After(users[j].CreatedAt)
+	})
+	return paginate(users, page, limit), int64(len(users)), nil
+}
+
+func (r *memoryUsers) CountExisting(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	var count int64
+	r.s.do(func(d *memoryData) error {
+		seen := make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			if _, ok := d.users[id]; ok && !seen[id] {
+				seen[id] = true
+				count++
+			}
+		}
+		return nil
+	})
+	return count, nil
+}
+
+func (r *memoryUsers) Create(ctx context.Context, user *models.User) error {
+	return r.s.do(func(d *memoryData) error {
+		return insertUser(d, user)
+	})
+}
+
+func (r *memoryUsers) Update(ctx context.Context, user *models.User) error {
+	return r.s.do(func(d *memoryData) error {
+		for _, existing := range d.users {
+			if existing.ID != user.ID && (existing.Username == user.Username || existing.Email == user.Email) {
+				return ErrDuplicate
+			}
+		}
+
+		user.UpdatedAt = time.Now()
+		d.users[user.ID] = *user
+		return nil
+	})
+}
+
+func (r *memoryUsers) TouchLastSeen(ctx context.Context, id uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		user, ok := d.users[id]
+		if !ok {
+			return nil
+		}
+		now := time.Now()
+		user.LastSeen = &now
+		d.users[id] = user
+		return nil
+	})
+}
+
+func (r *memoryUsers) ListDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error) {
+	var users []models.User
+	r.s.do(func(d *memoryData) error {
+		for _, user := range d.users {
+			if user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(now) {
+				users = append(users, user)
+			}
+		}
+		return nil
+	})
+	return users, nil
+}
+
+func (r *memoryUsers) HardDelete(ctx context.Context, id uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		for messageID, message := range d.messages {
+			if message.SenderID == id {
+				deleteAttachments(d, messageID)
+				delete(d.messages, messageID)
+			}
+		}
+		for memberID, member := range d.members {
+			if member.MemberID == id {
+				delete(d.members, memberID)
+			}
+		}
+		for exportID, export := range d.dataExports {
+			if export.UserID == id {
+				delete(d.dataExports, exportID)
+			}
+		}
+		removeCredentials(d, id)
+		delete(d.users, id)
+		return nil
+	})
+}
+
+func (r *memoryUsers) Anonymise(ctx context.Context, id uuid.UUID) error {
+	return r.s.do(func(d *memoryData) error {
+		for messageID, message := range d.messages {
+			if message.SenderID != id {
+				continue
+			}
+			// Deleted conversations are gone from the store, their messages can no longer be read
+			if conversation, ok := d.conversations[message.ConversationID]; ok && conversation.IsGroup {
+				continue
+			}
+			deleteAttachments(d, messageID)
+			delete(d.messages, messageID)
+		}
+		for memberID, member := range d.members {
+			if member.MemberID == id {
+				delete(d.members, memberID)
+			}
+		}
+		removeCredentials(d, id)
+
+		// The account is removed like any other deleted record, messages left in group
+		// conversations keep pointing at its ID
+		delete(d.users, id)
+		return nil
+	})
+}
+
+func (r *memoryUsers) ListOfflineIDs(ctx context.Context, ids []uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	var offline []uuid.UUID
+	r.s.do(func(d *memoryData) error {
+		for _, id := range ids {
+			user, ok := d.users[id]
+			if ok && (user.LastSeen == nil || user.LastSeen.Before(since)) {
+				offline = append(offline, id)
+			}
+		}
+		return nil
+	})
+	return offline, nil
+}
+
+func (r *memoryUsers) Locales(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	locales := make(map[uuid.UUID]string)
+	r.s.do(func(d *memoryData) error {
+		for _, id := range ids {
+			if user, ok := d.users[id]; ok && user.Locale != "" {
+				locales[id] = user.Locale
+			}
+		}
+		return nil
+	})
+	return locales, nil
+}
+
+// deleteAttachments removes the attachments of a message
+func deleteAttachments(d *memoryData, messageID uuid.UUID) {
+	for attachmentID, attachment := range d.attachments {
+		if attachment.MessageID == messageID {
+			delete(d.attachments, attachmentID)
+		}
+	}
+}
+
+// insertUser stores a new user, refusing a username or email that is already taken
+func insertUser(d *memoryData, user *models.User) error {
+	for _, existing := range d.users {
+		if existing.Username == user.Username || existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.Type == "" {
+		user.Type = enums.UserTypeHuman
+	}
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+
+	d.users[user.ID] = *user
+	return nil
+}
+
+// removeCredentials removes the push devices, API tokens and bot settings of a user
+func removeCredentials(d *memoryData, userID uuid.UUID) {
+	for id, device := range d.devices {
+		if device.UserID == userID {
+			delete(d.devices, id)
+		}
+	}
+	for id, token := range d.apiTokens {
+		if token.UserID == userID {
+			delete(d.apiTokens, id)
+		}
+	}
+	delete(d.bots, userID)
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"banter/models"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAnonymiseRemovesCredentials(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	user := &models.User{Username: "alice", Email: "alice@example.com"}
+	if err := store.Users().Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	other := &models.User{Username: "bob", Email: "bob@example.com"}
+	if err := store.Users().Create(ctx, other); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	for _, device := range []*models.Device{
+		{UserID: user.ID, Platform: "ios", Token: "alice-phone"},
+		{UserID: other.ID, Platform: "ios", Token: "bob-phone"},
+	} {
+		if err := store.Devices().Register(ctx, device); err != nil {
+			t.Fatalf("register device: %v", err)
+		}
+	}
+	token := &models.ApiToken{UserID: user.ID, Name: "cli", TokenHash: "hash", Scopes: "read"}
+	if err := store.ApiTokens().Create(ctx, token); err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	if err := store.Users().Anonymise(ctx, user.ID); err != nil {
+		t.Fatalf("anonymise: %v", err)
+	}
+
+	devices, _ := store.Devices().ListByUsers(ctx, []uuid.UUID{user.ID, other.ID})
+	if len(devices) != 1 || devices[0].UserID != other.ID {
+		t.Fatalf("got devices %v, want only bob's", devices)
+	}
+	if _, err := store.ApiTokens().GetByHash(ctx, "hash"); err != ErrNotFound {
+		t.Fatalf("got %v loading the anonymised user's token, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryWebhookSubscriptions struct {
+	s *MemoryStore
+}
+
+type memoryWebhookDeliveries struct {
+	s *MemoryStore
+}
+
+func (r *memoryWebhookSubscriptions) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.s.do(func(d *memoryData) error {
+		subscription.ID = uuid.New()
+		subscription.CreatedAt = time.Now()
+		subscription.UpdatedAt = subscription.CreatedAt
+		d.webhookSubscriptions[subscription.ID] = *subscription
+		return nil
+	})
+}
+
+func (r *memoryWebhookSubscriptions) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.s.do(func(d *memoryData) error {
+		if _, ok := d.webhookSubscriptions[subscription.ID]; !ok {
+			return ErrNotFound
+		}
+		subscription.UpdatedAt = time.Now()
+		d.webhookSubscriptions[subscription.ID] = *subscription
+		return nil
+	})
+}
+
+func (r *memoryWebhookSubscriptions) Delete(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.s.do(func(d *memoryData) error {
+		delete(d.webhookSubscriptions, subscription.ID)
+		return nil
+	})
+}
+
+func (r *memoryWebhookSubscriptions) GetByID(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.s.do(func(d *memoryData) error {
+		found, ok := d.webhookSubscriptions[id]
+		if !ok {
+			return ErrNotFound
+		}
+		subscription = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *memoryWebhookSubscriptions) List(ctx context.Context, activeOnly bool) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	r.s.do(func(d *memoryData) error {
+		for _, subscription := range d.webhookSubscriptions {
+			if !activeOnly || subscription.IsActive {
+				subscriptions = append(subscriptions, subscription)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+func (r *memoryWebhookDeliveries) Create(ctx context.Context, deliveries ...models.WebhookDelivery) error {
+	return r.s.do(func(d *memoryData) error {
+		now := time.Now()
+		for _, delivery := range deliveries {
+			if delivery.CreatedAt.IsZero() {
+				delivery.CreatedAt = now
+			}
+			delivery.UpdatedAt = now
+			d.webhookDeliveries[delivery.ID] = delivery
+		}
+		return nil
+	})
+}
+
+func (r *memoryWebhookDeliveries) Update(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.s.do(func(d *memoryData) error {
+		delivery.UpdatedAt = time.Now()
+		d.webhookDeliveries[delivery.ID] = *delivery
+		return nil
+	})
+}
+
+func (r *memoryWebhookDeliveries) GetByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.s.do(func(d *memoryData) error {
+		found, ok := d.webhookDeliveries[id]
+		if !ok {
+			return ErrNotFound
+		}
+		delivery = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *memoryWebhookDeliveries) ListDue(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	r.s.do(func(d *memoryData) error {
+		for _, delivery := range d.webhookDeliveries {
+			if delivery.Status == enums.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (r *memoryWebhookDeliveries) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	r.s.do(func(d *memoryData) error {
+		for _, delivery := range d.webhookDeliveries {
+			if delivery.Status == enums.DeliveryPending {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, nil
+}
+
+func (r *memoryWebhookDeliveries) Lease(ctx context.Context, delivery *models.WebhookDelivery, until time.Time) (bool, error) {
+	leased := false
+	r.s.do(func(d *memoryData) error {
+		stored, ok := d.webhookDeliveries[delivery.ID]
+		if ok && stored.Status == enums.DeliveryPending && stored.NextAttemptAt.Equal(delivery.NextAttemptAt) {
+			stored.NextAttemptAt = until
+			d.webhookDeliveries[delivery.ID] = stored
+			leased = true
+		}
+		return nil
+	})
+	delivery.NextAttemptAt = until
+	return leased, nil
+}
+
+func (r *memoryWebhookDeliveries) Filter(ctx context.Context, subscriptionID uuid.UUID, status enums.WebhookDeliveryStatus, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	r.s.do(func(d *memoryData) error {
+		for _, delivery := range d.webhookDeliveries {
+			if delivery.SubscriptionID != subscriptionID {
+				continue
+			}
+			if status != "" && delivery.Status != status {
+				continue
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		return nil
+	})
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	return paginate(deliveries, page, limit), int64(len(deliveries)), nil
+}
@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned when the requested record doesn't exist
+var ErrNotFound = errors.New("record not found")
+
+// Store gives access to every repository. GormStore keeps the data in the database, MemoryStore
+// keeps it in memory so the API can be exercised without one.
+type Store interface {
+	Users() UserRepository
+	Conversations() ConversationRepository
+	Members() MemberRepository
+	Messages() MessageRepository
+	Attachments() AttachmentRepository
+	Events() EventRepository
+	AuditLogs() AuditLogRepository
+	Devices() DeviceRepository
+	DataExports() DataExportRepository
+	Bots() BotRepository
+	ApiTokens() ApiTokenRepository
+	WebhookSubscriptions() WebhookSubscriptionRepository
+	WebhookDeliveries() WebhookDeliveryRepository
+
+	// Transaction runs fn with a store whose repositories all work in one transaction. The
+	// transaction is rolled back if fn returns an error.
+	Transaction(ctx context.Context, fn func(store Store) error) error
+}
+
+type UserRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	// GetByEmailOrUsername looks the user up by email first and by username second
+	GetByEmailOrUsername(ctx context.Context, email, username string) (*models.User, error)
+	Filter(ctx context.Context, filter models.UserFilter, page, limit int) ([]models.User, int64, error)
+	// CountExisting counts how many of the given users exist
+	CountExisting(ctx context.Context, ids []uuid.UUID) (int64, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	TouchLastSeen(ctx context.Context, id uuid.UUID) error
+	// ListDueForDeletion fetches the users whose deletion grace period has ended
+	ListDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error)
+	// HardDelete permanently erases the user together with their messages, attachments, memberships,
+	// devices and API tokens
+	HardDelete(ctx context.Context, id uuid.UUID) error
+	// Anonymise erases the user's personal data while keeping their group chat history readable.
+	// Messages in direct conversations are removed, messages in group conversations remain
+	// attributed to the anonymised account. Devices and API tokens are removed.
+	Anonymise(ctx context.Context, id uuid.UUID) error
+	// ListOfflineIDs returns the users among the given ones that haven't been active since the given time
+	ListOfflineIDs(ctx context.Context, ids []uuid.UUID, since time.Time) ([]uuid.UUID, error)
+	// Locales returns the locale picked by each of the given users, users without one are left out
+	Locales(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
+}
+
+type ConversationRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error)
+	// GetWithMembers fetches the conversation together with its members
+	GetWithMembers(ctx context.Context, id uuid.UUID) (*models.ConversationWithMembers, error)
+	// ListForUser fetches a page of the user's conversations. Pinned conversations come first,
+	// followed by the most recently active ones.
+	ListForUser(ctx context.Context, userID uuid.UUID, filter enums.ConversationFilter, cursor *models.ConversationCursor, limit int) (models.PaginatedConversations, error)
+	// ListAllForUser fetches every conversation the user is a member of
+	ListAllForUser(ctx context.Context, userID uuid.UUID) ([]models.Conversation, error)
+	Create(ctx context.Context, conversation *models.Conversation) error
+	// Delete deletes the conversation, its memberships are left to MemberRepository.RemoveAll
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type MemberRepository interface {
+	Get(ctx context.Context, conversationID, memberID uuid.UUID) (*models.ConversationMember, error)
+	Count(ctx context.Context, conversationID uuid.UUID) (int64, error)
+	Add(ctx context.Context, conversationID uuid.UUID, memberIDs []uuid.UUID) error
+	Update(ctx context.Context, member *models.ConversationMember) error
+	Remove(ctx context.Context, conversationID, memberID uuid.UUID) error
+	RemoveAll(ctx context.Context, conversationID uuid.UUID) error
+	// ListNotifiableIDs fetches the members that should be notified about activity in the
+	// conversation, leaving out the given user and anyone who has muted the conversation
+	ListNotifiableIDs(ctx context.Context, conversationID, excludeMemberID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type MessageRepository interface {
+	Create(ctx context.Context, message *models.Message) error
+	ListBySender(ctx context.Context, senderID uuid.UUID) ([]models.Message, error)
+}
+
+type AttachmentRepository interface {
+	ListByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]models.Attachment, error)
+}
+
+type EventRepository interface {
+	// Add writes events to the outbox
+	Add(ctx context.Context, events ...*models.OutboxEvent) error
+	// ListDue fetches unpublished events whose next attempt is due, oldest first
+	ListDue(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error)
+	CountUnpublished(ctx context.Context) (int64, error)
+	// Lease pushes the next attempt of an event back so no other relay picks it up while it is
+	// being published. It returns false if another relay leased it first.
+	Lease(ctx context.Context, event *models.OutboxEvent, until time.Time) (bool, error)
+	// Update saves the publication state of the event
+	Update(ctx context.Context, event *models.OutboxEvent) error
+	// DeletePublished removes events published before the given time
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+	// IsProcessed reports whether the consumer has already handled the event
+	IsProcessed(ctx context.Context, consumer string, eventID uuid.UUID) (bool, error)
+	MarkProcessed(ctx context.Context, consumer string, eventID uuid.UUID) error
+	// DeleteProcessed removes processed event records older than the given time
+	DeleteProcessed(ctx context.Context, before time.Time) (int64, error)
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *models.AuditLog) error
+	Filter(ctx context.Context, filter models.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error)
+}
+
+type DeviceRepository interface {
+	// Register stores the device, taking the token over if another user registered it before.
+	// An existing registration keeps its original ID.
+	Register(ctx context.Context, device *models.Device) error
+	ListByUsers(ctx context.Context, userIDs []uuid.UUID) ([]models.Device, error)
+	// DeleteForUser removes a device owned by the given user, reporting whether one was removed
+	DeleteForUser(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	// DeleteByToken removes a device whose token the push provider no longer accepts
+	DeleteByToken(ctx context.Context, token string) error
+}
+
+type DataExportRepository interface {
+	// Create inserts a pending export
+	Create(ctx context.Context, export *models.DataExport) error
+	Update(ctx context.Context, export *models.DataExport) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error)
+	ListByStatus(ctx context.Context, status enums.ExportStatus) ([]models.DataExport, error)
+	// ListExpired fetches completed exports whose download window has passed
+	ListExpired(ctx context.Context, now time.Time) ([]models.DataExport, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.DataExport, error)
+	// Claim moves a pending export to processing, returning false if another worker claimed it first
+	Claim(ctx context.Context, id uuid.UUID) (bool, error)
+	// ReleaseStale moves exports claimed before the given time back to pending, so exports whose
+	// worker died while processing them are picked up again. It returns how many were released.
+	ReleaseStale(ctx context.Context, claimedBefore time.Time) (int64, error)
+}
+
+type BotRepository interface {
+	// GetByUserID fetches a bot together with its user account
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Bot, error)
+	List(ctx context.Context) ([]models.Bot, error)
+	// ListForCommand fetches the bots that are members of the conversation, registered the command
+	// and have a webhook to receive it
+	ListForCommand(ctx context.Context, conversationID uuid.UUID, command string) ([]models.Bot, error)
+	// Create creates the bot together with its user account
+	Create(ctx context.Context, bot *models.Bot) error
+	Update(ctx context.Context, bot *models.Bot) error
+	// Delete deactivates the bot's user account and revokes all of its API tokens
+	Delete(ctx context.Context, bot *models.Bot) error
+}
+
+type ApiTokenRepository interface {
+	Create(ctx context.Context, token *models.ApiToken) error
+	// GetByHash fetches an API token by the hash of its value
+	GetByHash(ctx context.Context, hash string) (*models.ApiToken, error)
+	// ListByUser fetches every API token of a user, newest first
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.ApiToken, error)
+	// Revoke revokes one of the user's API tokens, reporting whether a token was revoked
+	Revoke(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	// Touch records that the token was just used
+	Touch(ctx context.Context, id uuid.UUID) error
+}
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *models.WebhookSubscription) error
+	Update(ctx context.Context, subscription *models.WebhookSubscription) error
+	Delete(ctx context.Context, subscription *models.WebhookSubscription) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error)
+	// List fetches every subscription, optionally only the active ones
+	List(ctx context.Context, activeOnly bool) ([]models.WebhookSubscription, error)
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, deliveries ...models.WebhookDelivery) error
+	Update(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error)
+	// ListDue fetches pending deliveries whose next attempt is due
+	ListDue(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	// CountPending counts the deliveries waiting to be sent or retried
+	CountPending(ctx context.Context) (int64, error)
+	// Lease pushes the next attempt of a delivery back so no other worker picks it up while it is
+	// being sent. It returns false if another worker leased it first.
+	Lease(ctx context.Context, delivery *models.WebhookDelivery, until time.Time) (bool, error)
+	// Filter fetches a page of deliveries of a subscription, newest first, along with the total count
+	Filter(ctx context.Context, subscriptionID uuid.UUID, status enums.WebhookDeliveryStatus, page, limit int) ([]models.WebhookDelivery, int64, error)
+}
@@ -1,6 +1,8 @@
 package responses
 
 import (
+	"banter/constants/enums"
+	"banter/i18n"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -11,18 +13,24 @@ type SuccessBody struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// FailureBody is sent for every failed request. Code is stable and meant to be matched on by clients,
+// Error and Message are for people and may change. Details is only set for invalid input.
 type FailureBody struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Message string `json:"message"`
+	Success bool            `json:"success"`
+	Code    enums.ErrorCode `json:"code" swaggertype:"string" example:"CONVERSATION_NOT_FOUND"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+	Details []FieldError    `json:"details,omitempty"`
 }
 
-// helper functions to get the basic success and failure responses skeleton
-func getFailureResponse(c *gin.Context, status int, error string, message string) {
+// helper functions to get the basic success and failure responses skeleton, the message is
+// translated to the locale of the request
+func getFailureResponse(c *gin.Context, status int, code enums.ErrorCode, error string, message string) {
 	c.JSON(status, FailureBody{
 		Success: false,
+		Code:    code,
 		Error:   error,
-		Message: message,
+		Message: i18n.Translate(c.Request.Context(), message),
 	})
 }
 
@@ -43,6 +51,10 @@ func Created(c *gin.Context, data gin.H) {
 	getSuccessResponse(c, http.StatusCreated, data)
 }
 
+func Accepted(c *gin.Context, data gin.H) {
+	getSuccessResponse(c, http.StatusAccepted, data)
+}
+
 func NoContent(c *gin.Context, data gin.H) {
 	getSuccessResponse(c, http.StatusNoContent, data)
 }
@@ -61,47 +73,63 @@ func Found(c *gin.Context, data gin.H) {
 }
 
 // client errors
-func BadRequest(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusBadRequest, error, message)
+func BadRequest(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusBadRequest, code, error, message)
+}
+
+func NotFound(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusNotFound, code, error, message)
+}
+
+func RequestTimeout(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusRequestTimeout, code, error, message)
 }
 
-func NotFound(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusNotFound, error, message)
+func TooManyRequests(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusTooManyRequests, code, error, message)
 }
 
-func RequestTimeout(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusRequestTimeout, error, message)
+func RequestEntityTooLarge(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusRequestEntityTooLarge, code, error, message)
 }
 
-func TooManyRequests(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusTooManyRequests, error, message)
+func UnavailableForLegalReasons(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusUnavailableForLegalReasons, code, error, message)
 }
 
-func UnavailableForLegalReasons(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusUnavailableForLegalReasons, error, message)
+func MethodNotAllowed(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusMethodNotAllowed, code, error, message)
 }
 
-func MethodNotAllowed(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusMethodNotAllowed, error, message)
+func Unauthorized(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusUnauthorized, code, error, message)
 }
 
-func Unauthorized(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusUnauthorized, error, message)
+func Forbidden(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusForbidden, code, error, message)
 }
 
-func Forbidden(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusForbidden, error, message)
+func Conflict(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusConflict, code, error, message)
 }
 
 // server errors
-func InternalServerError(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusInternalServerError, error, message)
+func InternalServerError(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusInternalServerError, code, error, message)
 }
 
-func NotImplemented(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusNotImplemented, error, message)
+func NotImplemented(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusNotImplemented, code, error, message)
 }
 
-func BadGateway(c *gin.Context, error string, message string) {
-	getFailureResponse(c, http.StatusBadGateway, error, message)
+func BadGateway(c *gin.Context, code enums.ErrorCode, error string, message string) {
+	getFailureResponse(c, http.StatusBadGateway, code, error, message)
+}
+
+// ServiceUnavailable keeps the success body so callers such as readiness probes can report details
+func ServiceUnavailable(c *gin.Context, data gin.H) {
+	c.JSON(http.StatusServiceUnavailable, SuccessBody{
+		Success: false,
+		Data:    data,
+	})
 }
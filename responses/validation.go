@@ -0,0 +1,188 @@
+package responses
+
+import (
+	"banter/constants/enums"
+	"banter/i18n"
+	"banter/utils/jsonguard"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes why a single field of the request was rejected. Rule is the validation that
+// failed, e.g. "required" or "max", and Param its argument if it has one.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// Report fields by the names clients send them as rather than the names of the struct fields
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// ValidationFailed answers a request whose body or query could not be bound, listing every field
+// that was rejected
+func ValidationFailed(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		RequestEntityTooLarge(c, enums.CodeBodyTooLarge, "Request Too Large", "Request body is too large")
+		return
+	}
+
+	locale := i18n.FromContext(c.Request.Context())
+	c.JSON(http.StatusBadRequest, FailureBody{
+		Success: false,
+		Code:    enums.CodeValidationFailed,
+		Error:   "Invalid Input",
+		Message: i18n.T(locale, validationMessage(err)),
+		Details: FieldErrors(locale, err),
+	})
+}
+
+func validationMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "Request body is empty"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "Request body is not valid JSON"
+	case errors.Is(err, jsonguard.ErrTooDeep):
+		return "Request body is nested too deeply"
+	}
+	return "One or more fields are invalid"
+}
+
+// FieldErrors returns the fields a binding error is about with messages in the given locale, nil if
+// it isn't about any field in particular
+func FieldErrors(locale string, err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			details = append(details, FieldError{
+				Field:   fieldPath(fieldErr),
+				Rule:    fieldErr.Tag(),
+				Param:   fieldErr.Param(),
+				Message: ruleMessage(locale, fieldErr),
+			})
+		}
+		return details
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: i18n.T(locale, "{field} must be of type {type}", "field", typeErr.Field, "type", jsonType(typeErr.Type)),
+		}}
+	}
+	return nil
+}
+
+// fieldPath returns the path of the field below the bound struct, e.g. "members[0]"
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+func ruleMessage(locale string, fieldErr validator.FieldError) string {
+	args := []string{"field", fieldPath(fieldErr), "param", fieldErr.Param()}
+	switch fieldErr.Tag() {
+	case "required":
+		return i18n.T(locale, "{field} is required", args...)
+	case "email":
+		return i18n.T(locale, "{field} must be a valid email address", args...)
+	case "url":
+		return i18n.T(locale, "{field} must be a valid URL", args...)
+	case "uuid":
+		return i18n.T(locale, "{field} must be a valid UUID", args...)
+	case "alpha":
+		return i18n.T(locale, "{field} must only contain letters", args...)
+	case "alphanum":
+		return i18n.T(locale, "{field} must only contain letters and numbers", args...)
+	case "numeric":
+		return i18n.T(locale, "{field} must be numeric", args...)
+	case "oneof":
+		args[3] = strings.Join(strings.Fields(fieldErr.Param()), ", ")
+		return i18n.T(locale, "{field} must be one of {param}", args...)
+	case "min", "max", "len":
+		return i18n.T(locale, limitMessages[fieldErr.Tag()][unit(fieldErr.Kind())], args...)
+	}
+	return i18n.T(locale, "{field} is invalid", args...)
+}
+
+// limitMessages holds the messages of the min, max and len rules by what their limit counts
+var limitMessages = map[string]map[string]string{
+	"min": {
+		"characters": "{field} must be at least {param} characters",
+		"items":      "{field} must have at least {param} items",
+		"":           "{field} must be at least {param}",
+	},
+	"max": {
+		"characters": "{field} must be at most {param} characters",
+		"items":      "{field} must have at most {param} items",
+		"":           "{field} must be at most {param}",
+	},
+	"len": {
+		"characters": "{field} must be exactly {param} characters",
+		"items":      "{field} must have exactly {param} items",
+		"":           "{field} must be exactly {param}",
+	},
+}
+
+// unit returns what the limit of a min, max or len rule counts for a field of the given kind
+func unit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	}
+	return ""
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
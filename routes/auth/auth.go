@@ -10,9 +10,9 @@ import (
 
 var RouteGroupName = "/auth"
 
-func Routes(router *gin.RouterGroup) {
-	router.Handle(http.MethodPost, "/login", handlers.LoginHandler)
-	router.Handle(http.MethodPost, "/register", handlers.RegisterHandler)
+func Routes(router *gin.RouterGroup, h *handlers.Handler) {
+	router.Handle(http.MethodPost, "/login", h.LoginHandler)
+	router.Handle(http.MethodPost, "/register", h.RegisterHandler)
 
 	return
 }
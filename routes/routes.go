@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"banter/handlers"
+	"banter/middlewares"
+	"banter/routes/auth"
+	v1 "banter/routes/v1"
+	"banter/utils/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register adds every API route to the router, served by the given handlers
+func Register(router *gin.Engine, h *handlers.Handler) {
+	// Security headers and body limits for every route, routes taking larger bodies raise their own
+	security := config.Configs.Security
+	router.Use(middlewares.SecurityHeadersMiddleware(security))
+	router.Use(middlewares.JSONDepthMiddleware(security.MaxJsonDepth))
+	router.Use(middlewares.BodyLimitMiddleware(security.MaxBodySizeInKb))
+
+	// Probes, /health is kept for monitors that predate the split
+	router.GET("/health", h.LivenessHandler)
+	router.GET("/health/live", h.LivenessHandler)
+	router.GET("/health/ready", h.ReadinessHandler)
+
+	auth.Routes(router.Group(auth.RouteGroupName), h)
+	v1.ApiDocRoutes(router.Group(v1.RouteGroupName))
+	v1.UserRoutes(router.Group(v1.RouteGroupName), h)
+	v1.ConversationRoutes(router.Group(v1.RouteGroupName), h)
+	v1.DeviceRoutes(router.Group(v1.RouteGroupName), h)
+	v1.AccountRoutes(router.Group(v1.RouteGroupName), h)
+	v1.AdminRoutes(router.Group(v1.RouteGroupName), h)
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"banter/handlers"
 	"banter/middlewares"
+	"banter/utils/config"
 	"net/http"
 
 	_ "banter/docs"
@@ -14,26 +15,89 @@ import (
 
 var RouteGroupName = "/v1"
 
-func UserRoutes(router *gin.RouterGroup) {
-	router.Use(middlewares.JWTMiddleware())
+func UserRoutes(router *gin.RouterGroup, h *handlers.Handler) {
+	router.Use(middlewares.JWTMiddleware(h.Store()))
 	{
 		// User related routes
-		router.Handle(http.MethodGet, "/user/:id", handlers.GetUserDetailsHandler)
-		router.Handle(http.MethodPatch, "/user/:id", handlers.UpdateUserDetailsHandler)
+		router.Handle(http.MethodGet, "/user/:id", h.GetUserDetailsHandler)
+		router.Handle(http.MethodPatch, "/user/:id", h.UpdateUserDetailsHandler)
 
 	}
 }
 
-func ConversationRoutes(router *gin.RouterGroup) {
-	router.Use(middlewares.JWTMiddleware())
+func ConversationRoutes(router *gin.RouterGroup, h *handlers.Handler) {
+	router.Use(middlewares.JWTMiddleware(h.Store()))
 	{
 		// User related routes
-		router.Handle(http.MethodPost, "/conversation", handlers.StartConversationHandler)
-		router.Handle(http.MethodGet, "/conversations/member/:user_id", handlers.GetConversationsHandler)
-		router.Handle(http.MethodGet, "/conversation/:id", handlers.GetConversationHandler)
-		router.Handle(http.MethodPost, "/conversation/:id/member/:user_id", handlers.AddMemberHandler)
-		router.Handle(http.MethodDelete, "/conversation/:id/member/:user_id", handlers.RemoveMemberHandler)
-		router.Handle(http.MethodDelete, "/conversation/:id", handlers.DeleteConversationHandler)
+		router.Handle(http.MethodPost, "/conversation", h.StartConversationHandler)
+		router.Handle(http.MethodGet, "/conversations/member/:user_id", h.GetConversationsHandler)
+		router.Handle(http.MethodGet, "/conversation/:id", h.GetConversationHandler)
+		router.Handle(http.MethodPatch, "/conversation/:id/settings", h.UpdateConversationSettingsHandler)
+		router.Handle(http.MethodPost, "/conversation/:id/messages",
+			middlewares.BodyLimitMiddleware(config.Configs.Security.MaxMessageBodySizeInKb), h.SendMessageHandler)
+		router.Handle(http.MethodPost, "/conversation/:id/member/:user_id", h.AddMemberHandler)
+		router.Handle(http.MethodDelete, "/conversation/:id/member/:user_id", h.RemoveMemberHandler)
+		router.Handle(http.MethodDelete, "/conversation/:id", h.DeleteConversationHandler)
+
+	}
+}
+
+func DeviceRoutes(router *gin.RouterGroup, h *handlers.Handler) {
+	router.Use(middlewares.JWTMiddleware(h.Store()))
+	{
+		// Push notification device routes
+		router.Handle(http.MethodPost, "/devices", h.RegisterDeviceHandler)
+		router.Handle(http.MethodGet, "/devices", h.GetDevicesHandler)
+		router.Handle(http.MethodDelete, "/devices/:id", h.DeleteDeviceHandler)
+
+	}
+}
+
+func AccountRoutes(router *gin.RouterGroup, h *handlers.Handler) {
+	router.Use(middlewares.JWTMiddleware(h.Store()))
+	{
+		// Account self-service routes
+		router.Handle(http.MethodPost, "/account/deletion", h.ScheduleAccountDeletionHandler)
+		router.Handle(http.MethodDelete, "/account/deletion", h.CancelAccountDeletionHandler)
+		router.Handle(http.MethodPost, "/account/exports", h.RequestDataExportHandler)
+		router.Handle(http.MethodGet, "/account/exports/:id", h.GetDataExportHandler)
+		router.Handle(http.MethodGet, "/account/exports/:id/download", h.DownloadDataExportHandler)
+
+	}
+}
+
+func AdminRoutes(router *gin.RouterGroup, h *handlers.Handler) {
+	router.Use(middlewares.JWTMiddleware(h.Store()), middlewares.StaffMiddleware())
+	{
+		// User lifecycle management routes
+		router.Handle(http.MethodGet, "/admin/users", h.ListUsersHandler)
+		router.Handle(http.MethodPost, "/admin/users/:id/ban", h.BanUserHandler)
+		router.Handle(http.MethodPost, "/admin/users/:id/unban", h.UnbanUserHandler)
+		router.Handle(http.MethodPost, "/admin/users/:id/deactivate", h.DeactivateUserHandler)
+		router.Handle(http.MethodPost, "/admin/users/:id/logout", h.ForceLogoutHandler)
+		router.Handle(http.MethodPost, "/admin/users/:id/password-reset", h.ForcePasswordResetHandler)
+		router.Handle(http.MethodDelete, "/admin/users/:id", h.HardDeleteUserHandler)
+
+		// Audit log routes
+		router.Handle(http.MethodGet, "/admin/audit-logs", h.ListAuditLogsHandler)
+
+		// Webhook routes
+		router.Handle(http.MethodPost, "/admin/webhooks", h.CreateWebhookHandler)
+		router.Handle(http.MethodGet, "/admin/webhooks", h.GetWebhooksHandler)
+		router.Handle(http.MethodGet, "/admin/webhooks/:id", h.GetWebhookHandler)
+		router.Handle(http.MethodPatch, "/admin/webhooks/:id", h.UpdateWebhookHandler)
+		router.Handle(http.MethodDelete, "/admin/webhooks/:id", h.DeleteWebhookHandler)
+		router.Handle(http.MethodGet, "/admin/webhooks/:id/deliveries", h.GetWebhookDeliveriesHandler)
+		router.Handle(http.MethodPost, "/admin/webhook-deliveries/:id/replay", h.ReplayWebhookDeliveryHandler)
+
+		// Bot routes
+		router.Handle(http.MethodPost, "/admin/bots", h.CreateBotHandler)
+		router.Handle(http.MethodGet, "/admin/bots", h.GetBotsHandler)
+		router.Handle(http.MethodPatch, "/admin/bots/:id", h.UpdateBotHandler)
+		router.Handle(http.MethodDelete, "/admin/bots/:id", h.DeleteBotHandler)
+		router.Handle(http.MethodPost, "/admin/bots/:id/tokens", h.CreateApiTokenHandler)
+		router.Handle(http.MethodGet, "/admin/bots/:id/tokens", h.GetApiTokensHandler)
+		router.Handle(http.MethodDelete, "/admin/bots/:id/tokens/:token_id", h.RevokeApiTokenHandler)
 
 	}
 }
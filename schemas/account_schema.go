@@ -0,0 +1,6 @@
+package schemas
+
+// DeleteAccountSchema holds the password confirming an account deletion request
+type DeleteAccountSchema struct {
+	Password string `json:"password" binding:"required,min=8,max=32"`
+}
@@ -0,0 +1,21 @@
+package schemas
+
+import (
+	"time"
+)
+
+// AdminUserFilterSchema holds the query parameters used to list users
+type AdminUserFilterSchema struct {
+	Status      string    `form:"status" binding:"omitempty,oneof=active inactive banned"`
+	IsStaff     *bool     `form:"is_staff" binding:"omitempty"`
+	CreatedFrom time.Time `form:"created_from" time_format:"02-01-2006" binding:"omitempty"`
+	CreatedTo   time.Time `form:"created_to" time_format:"02-01-2006" binding:"omitempty"`
+	Page        int       `form:"page" binding:"omitempty,min=1"`
+	Limit       int       `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// UserStatusChangeSchema holds the reason and optional expiry of a ban, unban or deactivation
+type UserStatusChangeSchema struct {
+	Reason    string     `json:"reason" binding:"required,min=3,max=500"`
+	ExpiresAt *time.Time `json:"expires_at" binding:"omitempty"`
+}
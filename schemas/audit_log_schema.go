@@ -0,0 +1,17 @@
+package schemas
+
+import (
+	"time"
+)
+
+// AuditLogFilterSchema holds the query parameters used to search the audit log
+type AuditLogFilterSchema struct {
+	ActorID    string    `form:"actor_id" binding:"omitempty,uuid"`
+	TargetType string    `form:"target_type" binding:"omitempty,max=50"`
+	TargetID   string    `form:"target_id" binding:"omitempty,max=64"`
+	Action     string    `form:"action" binding:"omitempty,max=50"`
+	From       time.Time `form:"from" binding:"omitempty"`
+	To         time.Time `form:"to" binding:"omitempty"`
+	Page       int       `form:"page" binding:"omitempty,min=1"`
+	Limit      int       `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,24 @@
+package schemas
+
+type CreateBotSchema struct {
+	Username           string   `json:"username" binding:"required,alphanum,min=3,max=50"`
+	DisplayName        string   `json:"display_name" binding:"required,min=2,max=50"`
+	Description        string   `json:"description" binding:"omitempty,max=500"`
+	WebhookURL         string   `json:"webhook_url" binding:"omitempty,url,max=2048"`
+	Commands           []string `json:"commands" binding:"omitempty,max=32,dive,min=1,max=32"`
+	RateLimitPerMinute int      `json:"rate_limit_per_minute" binding:"omitempty,min=1,max=10000"`
+}
+
+type UpdateBotSchema struct {
+	DisplayName        *string  `json:"display_name" binding:"omitempty,min=2,max=50"`
+	Description        *string  `json:"description" binding:"omitempty,max=500"`
+	WebhookURL         *string  `json:"webhook_url" binding:"omitempty,max=2048"`
+	Commands           []string `json:"commands" binding:"omitempty,max=32,dive,min=1,max=32"`
+	RateLimitPerMinute *int     `json:"rate_limit_per_minute" binding:"omitempty,min=0,max=10000"`
+}
+
+type CreateApiTokenSchema struct {
+	Name          string   `json:"name" binding:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
@@ -0,0 +1,12 @@
+package schemas
+
+import (
+	"time"
+)
+
+type ConversationSettingsSchema struct {
+	Pinned     *bool      `json:"pinned" binding:"omitempty"`
+	Archived   *bool      `json:"archived" binding:"omitempty"`
+	MutedUntil *time.Time `json:"muted_until" binding:"omitempty"`
+	Unmute     bool       `json:"unmute" binding:"omitempty"`
+}
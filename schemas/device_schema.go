@@ -0,0 +1,7 @@
+package schemas
+
+type RegisterDeviceSchema struct {
+	Platform   string `json:"platform" binding:"required,oneof=android ios web"`
+	Token      string `json:"token" binding:"required,max=4096"`
+	AppVersion string `json:"app_version" binding:"omitempty,max=32"`
+}
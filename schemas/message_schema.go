@@ -0,0 +1,5 @@
+package schemas
+
+type SendMessageSchema struct {
+	Content string `json:"content" binding:"required,min=1,max=65536"`
+}
@@ -0,0 +1,20 @@
+package schemas
+
+type CreateWebhookSchema struct {
+	URL    string   `json:"url" binding:"required,url,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=* conversation.created conversation.deleted conversation.member_added conversation.member_removed message.created"`
+	Secret string   `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+type UpdateWebhookSchema struct {
+	URL      *string  `json:"url" binding:"omitempty,url,max=2048"`
+	Events   []string `json:"events" binding:"omitempty,min=1,dive,oneof=* conversation.created conversation.deleted conversation.member_added conversation.member_removed message.created"`
+	Secret   *string  `json:"secret" binding:"omitempty,min=16,max=128"`
+	IsActive *bool    `json:"is_active" binding:"omitempty"`
+}
+
+type WebhookDeliveryFilterSchema struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
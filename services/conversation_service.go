@@ -0,0 +1,212 @@
+package services
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/repositories"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ConversationService manages conversations and their members
+type ConversationService struct {
+	store repositories.Store
+}
+
+// NewConversationService creates a conversation service using the given store
+func NewConversationService(store repositories.Store) *ConversationService {
+	return &ConversationService{store: store}
+}
+
+// StartConversation creates a conversation together with its members
+func (s *ConversationService) StartConversation(ctx context.Context, creator *models.User, name string, isGroup bool, memberIDs []uuid.UUID) (*models.Conversation, error) {
+	memberIDs = uniqueIDs(memberIDs)
+
+	// Ensure there are at least 3 members in a group chat
+	if isGroup && len(memberIDs) < 3 {
+		return nil, ErrGroupTooSmall
+	}
+
+	conversation := &models.Conversation{
+		ID:      uuid.New(),
+		Name:    name,
+		IsGroup: isGroup,
+	}
+
+	event, err := models.NewOutboxEvent(enums.EventConversationCreated, "conversation", conversation.ID, map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"name":            conversation.Name,
+		"is_group":        conversation.IsGroup,
+		"created_by_id":   creator.ID,
+		"member_ids":      memberIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.Transaction(ctx, func(store repositories.Store) error {
+		count, err := store.Users().CountExisting(ctx, memberIDs)
+		if err != nil {
+			return err
+		}
+		if count != int64(len(memberIDs)) {
+			return ErrUserNotFound
+		}
+
+		if err := store.Conversations().Create(ctx, conversation); err != nil {
+			return err
+		}
+		if err := store.Members().Add(ctx, conversation.ID, memberIDs); err != nil {
+			return err
+		}
+		return store.Events().Add(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// GetConversation fetches a conversation the actor is a member of along with its members
+func (s *ConversationService) GetConversation(ctx context.Context, actor *models.User, conversationID uuid.UUID) (*models.ConversationWithMembers, error) {
+	if err := requireMember(ctx, s.store, conversationID, actor.ID); err != nil {
+		return nil, err
+	}
+	return s.store.Conversations().GetWithMembers(ctx, conversationID)
+}
+
+// AddMember adds a user to a conversation the actor is a member of
+func (s *ConversationService) AddMember(ctx context.Context, actor *models.User, conversationID, userID uuid.UUID) error {
+	event, err := models.NewOutboxEvent(enums.EventMemberAdded, "conversation", conversationID, map[string]interface{}{
+		"conversation_id": conversationID,
+		"member_id":       userID,
+		"actor_id":        actor.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(ctx, func(store repositories.Store) error {
+		if err := requireMember(ctx, store, conversationID, actor.ID); err != nil {
+			return err
+		}
+
+		count, err := store.Users().CountExisting(ctx, []uuid.UUID{userID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrUserNotFound
+		}
+
+		if _, err := store.Members().Get(ctx, conversationID, userID); err == nil {
+			return ErrAlreadyMember
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+
+		if err := store.Members().Add(ctx, conversationID, []uuid.UUID{userID}); err != nil {
+			return err
+		}
+		return store.Events().Add(ctx, event)
+	})
+}
+
+// RemoveMember removes a user from a conversation the actor is a member of. A conversation keeps
+// at least 2 members.
+func (s *ConversationService) RemoveMember(ctx context.Context, actor *models.User, conversationID, userID uuid.UUID) error {
+	event, err := models.NewOutboxEvent(enums.EventMemberRemoved, "conversation", conversationID, map[string]interface{}{
+		"conversation_id": conversationID,
+		"member_id":       userID,
+		"actor_id":        actor.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(ctx, func(store repositories.Store) error {
+		if err := requireMember(ctx, store, conversationID, actor.ID); err != nil {
+			return err
+		}
+
+		if _, err := store.Members().Get(ctx, conversationID, userID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrMemberNotFound
+			}
+			return err
+		}
+
+		// Prevent removal if only 2 members are left
+		count, err := store.Members().Count(ctx, conversationID)
+		if err != nil {
+			return err
+		}
+		if count <= 2 {
+			return ErrTooFewMembers
+		}
+
+		if err := store.Members().Remove(ctx, conversationID, userID); err != nil {
+			return err
+		}
+		return store.Events().Add(ctx, event)
+	})
+}
+
+// DeleteConversation deletes a conversation the actor is a member of along with its memberships
+func (s *ConversationService) DeleteConversation(ctx context.Context, actor *models.User, conversationID uuid.UUID) error {
+	event, err := models.NewOutboxEvent(enums.EventConversationDeleted, "conversation", conversationID, map[string]interface{}{
+		"conversation_id": conversationID,
+		"actor_id":        actor.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(ctx, func(store repositories.Store) error {
+		if err := requireMember(ctx, store, conversationID, actor.ID); err != nil {
+			return err
+		}
+
+		if err := store.Conversations().Delete(ctx, conversationID); err != nil {
+			return err
+		}
+		if err := store.Members().RemoveAll(ctx, conversationID); err != nil {
+			return err
+		}
+		return store.Events().Add(ctx, event)
+	})
+}
+
+// requireMember checks the conversation exists and the user is one of its members
+func requireMember(ctx context.Context, store repositories.Store, conversationID, userID uuid.UUID) error {
+	if _, err := store.Conversations().GetByID(ctx, conversationID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrConversationNotFound
+		}
+		return err
+	}
+
+	if _, err := store.Members().Get(ctx, conversationID, userID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrNotAMember
+		}
+		return err
+	}
+	return nil
+}
+
+// uniqueIDs removes duplicate IDs, keeping the first occurrence
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
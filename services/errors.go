@@ -0,0 +1,34 @@
+package services
+
+import "banter/constants/enums"
+
+// ErrorKind classifies domain errors so callers can react to them without matching each one
+type ErrorKind int
+
+const (
+	KindInvalid ErrorKind = iota + 1
+	KindNotFound
+	KindForbidden
+	KindConflict
+)
+
+// Error is a domain error returned by the services, Code is what API clients see it as
+type Error struct {
+	Kind    ErrorKind
+	Code    enums.ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	ErrConversationNotFound = &Error{Kind: KindNotFound, Code: enums.CodeConversationNotFound, Message: "No conversation found with the given ID"}
+	ErrUserNotFound         = &Error{Kind: KindNotFound, Code: enums.CodeUserNotFound, Message: "One or more users do not exist"}
+	ErrMemberNotFound       = &Error{Kind: KindNotFound, Code: enums.CodeMemberNotFound, Message: "User is not a member of this conversation"}
+	ErrNotAMember           = &Error{Kind: KindForbidden, Code: enums.CodeNotAMember, Message: "You are not a member of this conversation"}
+	ErrAlreadyMember        = &Error{Kind: KindConflict, Code: enums.CodeAlreadyAMember, Message: "User is already a member of this conversation"}
+	ErrGroupTooSmall        = &Error{Kind: KindInvalid, Code: enums.CodeGroupTooSmall, Message: "Group chats must have at least 3 members"}
+	ErrTooFewMembers        = &Error{Kind: KindConflict, Code: enums.CodeTooFewMembers, Message: "Cannot remove member, only 2 members left"}
+)
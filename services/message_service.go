@@ -0,0 +1,62 @@
+package services
+
+import (
+	"banter/constants/enums"
+	"banter/metrics"
+	"banter/models"
+	"banter/repositories"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageService posts messages to conversations
+type MessageService struct {
+	store repositories.Store
+}
+
+// NewMessageService creates a message service using the given store
+func NewMessageService(store repositories.Store) *MessageService {
+	return &MessageService{store: store}
+}
+
+// SendMessage posts a message from the sender to a conversation they are a member of
+func (s *MessageService) SendMessage(ctx context.Context, sender *models.User, conversationID uuid.UUID, content string) (*models.Message, error) {
+	message := &models.Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		SenderID:       sender.ID,
+		Content:        content,
+		CreatedAt:      time.Now(),
+	}
+
+	event, err := models.NewOutboxEvent(enums.EventMessageCreated, "conversation", conversationID, map[string]interface{}{
+		"message_id":      message.ID,
+		"conversation_id": conversationID,
+		"sender_id":       sender.ID,
+		"sender_type":     sender.Type,
+		"content":         message.Content,
+		"created_at":      message.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.Transaction(ctx, func(store repositories.Store) error {
+		if err := requireMember(ctx, store, conversationID, sender.ID); err != nil {
+			return err
+		}
+
+		if err := store.Messages().Create(ctx, message); err != nil {
+			return err
+		}
+		return store.Events().Add(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.MessageSent()
+	return message, nil
+}
@@ -2,10 +2,21 @@ package stores
 
 import (
 	"banter/utils/config"
+	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
 var (
@@ -15,15 +26,30 @@ var (
 
 func GetDb() *gorm.DB {
 	once.Do(func() {
-		// Replace with your MySQL connection details
-		dsn := config.Configs.Stores.Postgres.ConnectionString
+		dialector, err := openDialector()
+		if err != nil {
+			panic(err)
+		}
 
-		// Open a connection to MySQL
-		newDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		slowThreshold := time.Duration(config.Configs.Stores.SlowQueryThresholdInMs) * time.Millisecond
+		newDB, err := gorm.Open(dialector, &gorm.Config{Logger: newSlogLogger(slowThreshold)})
 		if err != nil {
 			panic(err) // or handle the error accordingly
 		}
 
+		// IDs are generated here rather than by the database so every driver behaves the same
+		err = newDB.Callback().Create().Before("gorm:create").Register("banter:assign_uuid", assignUUID)
+		if err != nil {
+			panic(err)
+		}
+		// Registered before tracing so the callbacks restore the statement's context in reverse order
+		if err := registerQueryTimeout(newDB); err != nil {
+			panic(err)
+		}
+		if err := registerTracing(newDB); err != nil {
+			panic(err)
+		}
+
 		// Set connection pool settings
 		sqlDB, err := newDB.DB()
 		if err != nil {
@@ -37,3 +63,71 @@ func GetDb() *gorm.DB {
 
 	return db
 }
+
+// Close closes the connection pool if it was opened. Queries still running are allowed to finish.
+func Close() error {
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Driver returns the configured database driver, postgres unless set otherwise
+func Driver() string {
+	if config.Configs.Stores.Driver == "" {
+		return DriverPostgres
+	}
+	return config.Configs.Stores.Driver
+}
+
+// openDialector returns the dialector of the configured driver
+func openDialector() (gorm.Dialector, error) {
+	switch Driver() {
+	case DriverPostgres:
+		return postgres.Open(config.Configs.Stores.Postgres.ConnectionString), nil
+	case DriverSQLite:
+		path := config.Configs.Stores.Sqlite.Path
+		if path == "" {
+			path = "banter.db"
+		}
+		// Wait for locks instead of failing right away as the workers write concurrently, and
+		// enforce foreign keys which SQLite leaves off by default
+		dsn := path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported stores driver %q, use %s or %s", Driver(), DriverPostgres, DriverSQLite)
+	}
+}
+
+// assignUUID gives records with a UUID primary key a new ID when they are created without one
+func assignUUID(tx *gorm.DB) {
+	if tx.Statement.Schema == nil {
+		return
+	}
+	field := tx.Statement.Schema.PrioritizedPrimaryField
+	if field == nil || field.FieldType != reflect.TypeOf(uuid.UUID{}) {
+		return
+	}
+
+	value := tx.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			setUUID(tx, field, reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		setUUID(tx, field, value)
+	}
+}
+
+func setUUID(tx *gorm.DB, field *schema.Field, record reflect.Value) {
+	if _, isZero := field.ValueOf(tx.Statement.Context, record); isZero {
+		if err := field.Set(tx.Statement.Context, record, uuid.New()); err != nil {
+			tx.AddError(err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slogLogger writes gorm's logs through slog, so queries run with a request's context carry its
+// request and user IDs. Failed queries are logged as errors, slow ones as warnings and the rest at
+// debug level.
+type slogLogger struct {
+	slowThreshold time.Duration
+	level         gormlogger.LogLevel
+}
+
+func newSlogLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &slogLogger{slowThreshold: slowThreshold, level: gormlogger.Info}
+}
+
+func (l *slogLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		log(ctx, slog.LevelInfo, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		log(ctx, slog.LevelWarn, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		log(ctx, slog.LevelError, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	// Clients going away and queries running past their deadline are expected under load
+	case (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log(ctx, slog.LevelWarn, "Query cancelled", "error", err, "sql", sql, "rows", rows, "duration_ms", milliseconds(elapsed))
+	// Lookups that find nothing are answered with a 404, not worth an error
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		log(ctx, slog.LevelError, "Query failed", "error", err, "sql", sql, "rows", rows, "duration_ms", milliseconds(elapsed))
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log(ctx, slog.LevelWarn, "Slow query", "sql", sql, "rows", rows, "duration_ms", milliseconds(elapsed))
+	case l.level >= gormlogger.Info && slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		log(ctx, slog.LevelDebug, "Query", "sql", sql, "rows", rows, "duration_ms", milliseconds(elapsed))
+	}
+}
+
+// log writes the record with the code that ran the query as its caller, rather than this file
+func log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	handler := slog.Default().Handler()
+	if !handler.Enabled(ctx, level) {
+		return
+	}
+
+	record := slog.NewRecord(time.Now(), level, msg, 0)
+	record.Add(args...)
+	record.AddAttrs(slog.String("caller", queryCaller()))
+	_ = handler.Handle(ctx, record)
+}
+
+// queryCaller returns the file and line of the first frame outside gorm and this package
+func queryCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "gorm.io/") && !strings.HasPrefix(frame.Function, "banter/stores.") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,86 @@
+package stores
+
+import (
+	"banter/utils/config"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const queryDeadlineKey = "banter:query_deadline"
+
+type queryTimeoutKey struct{}
+
+// queryDeadline is kept on the statement between the callbacks around a query
+type queryDeadline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	parent context.Context
+}
+
+// WithQueryTimeout returns a context whose queries may run for the given time instead of the
+// configured stores.query_timeout_in_ms, zero lets them run as long as ctx allows
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// QueryTimeout returns the time a single query may take by default, zero if unlimited
+func QueryTimeout() time.Duration {
+	return time.Duration(config.Configs.Stores.QueryTimeoutInMs) * time.Millisecond
+}
+
+// registerQueryTimeout gives every create, query, update and delete statement its own deadline on
+// top of the one of the context it was run with. Raw statements are left alone, they are run by
+// migrations and scanned rows are read after the callbacks have returned.
+func registerQueryTimeout(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("banter:start_create_deadline", startQueryDeadline),
+		callbacks.Create().After("gorm:create").Register("banter:end_create_deadline", endQueryDeadline),
+		callbacks.Query().Before("gorm:query").Register("banter:start_query_deadline", startQueryDeadline),
+		callbacks.Query().After("gorm:query").Register("banter:end_query_deadline", endQueryDeadline),
+		callbacks.Update().Before("gorm:update").Register("banter:start_update_deadline", startQueryDeadline),
+		callbacks.Update().After("gorm:update").Register("banter:end_update_deadline", endQueryDeadline),
+		callbacks.Delete().Before("gorm:delete").Register("banter:start_delete_deadline", startQueryDeadline),
+		callbacks.Delete().After("gorm:delete").Register("banter:end_delete_deadline", endQueryDeadline),
+	)
+}
+
+func startQueryDeadline(tx *gorm.DB) {
+	parent := tx.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	timeout := QueryTimeout()
+	if override, ok := parent.Value(queryTimeoutKey{}).(time.Duration); ok {
+		timeout = override
+	}
+	if timeout <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	tx.InstanceSet(queryDeadlineKey, queryDeadline{ctx: ctx, cancel: cancel, parent: tx.Statement.Context})
+	tx.Statement.Context = ctx
+}
+
+func endQueryDeadline(tx *gorm.DB) {
+	value, _ := tx.InstanceGet(queryDeadlineKey)
+	deadline, ok := value.(queryDeadline)
+	if !ok {
+		return
+	}
+	tx.InstanceSet(queryDeadlineKey, nil)
+	tx.Statement.Context = deadline.parent
+
+	// Drivers report an interrupted query in their own words, wrap it so callers can tell a timeout
+	// or cancellation apart from other failures with errors.Is
+	if err := deadline.ctx.Err(); err != nil && tx.Error != nil && !errors.Is(tx.Error, err) {
+		tx.Error = fmt.Errorf("%w: %w", err, tx.Error)
+	}
+	deadline.cancel()
+}
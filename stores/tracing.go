@@ -0,0 +1,99 @@
+package stores
+
+import (
+	"banter/tracing"
+	"context"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const querySpanKey = "banter:query_span"
+
+// querySpan is kept on the statement between the callbacks around a query
+type querySpan struct {
+	span   trace.Span
+	parent context.Context
+}
+
+// registerTracing wraps every SQL statement in a span, a child of the span found in the context the
+// query was run with. Statements run outside of a trace, such as the polling of background workers,
+// are left out so they don't each start a trace of their own.
+func registerTracing(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("banter:start_create_span", startQuerySpan),
+		callbacks.Create().After("gorm:create").Register("banter:end_create_span", endQuerySpan),
+		callbacks.Query().Before("gorm:query").Register("banter:start_query_span", startQuerySpan),
+		callbacks.Query().After("gorm:query").Register("banter:end_query_span", endQuerySpan),
+		callbacks.Update().Before("gorm:update").Register("banter:start_update_span", startQuerySpan),
+		callbacks.Update().After("gorm:update").Register("banter:end_update_span", endQuerySpan),
+		callbacks.Delete().Before("gorm:delete").Register("banter:start_delete_span", startQuerySpan),
+		callbacks.Delete().After("gorm:delete").Register("banter:end_delete_span", endQuerySpan),
+		callbacks.Row().Before("gorm:row").Register("banter:start_row_span", startQuerySpan),
+		callbacks.Row().After("gorm:row").Register("banter:end_row_span", endQuerySpan),
+		callbacks.Raw().Before("gorm:raw").Register("banter:start_raw_span", startQuerySpan),
+		callbacks.Raw().After("gorm:raw").Register("banter:end_raw_span", endQuerySpan),
+	)
+}
+
+func startQuerySpan(tx *gorm.DB) {
+	parent := tx.Statement.Context
+	if parent == nil || !trace.SpanContextFromContext(parent).IsValid() {
+		return
+	}
+	// The name is set once the SQL is built
+	ctx, span := tracing.Tracer().Start(parent, "db", trace.WithSpanKind(trace.SpanKindClient))
+	tx.InstanceSet(querySpanKey, querySpan{span: span, parent: parent})
+	tx.Statement.Context = ctx
+}
+
+func endQuerySpan(tx *gorm.DB) {
+	value, _ := tx.InstanceGet(querySpanKey)
+	query, ok := value.(querySpan)
+	if !ok {
+		return
+	}
+	tx.InstanceSet(querySpanKey, nil)
+	// Restore the context so later statements on the same session aren't nested under this one
+	tx.Statement.Context = query.parent
+	defer query.span.End()
+
+	if !query.span.IsRecording() {
+		return
+	}
+
+	sql := tx.Statement.SQL.String()
+	operation, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	operation = strings.ToUpper(operation)
+	name := operation
+	if tx.Statement.Table != "" {
+		name += " " + tx.Statement.Table
+	}
+
+	query.span.SetName(name)
+	query.span.SetAttributes(
+		dbSystem(),
+		semconv.DBOperationName(operation),
+		semconv.DBCollectionName(tx.Statement.Table),
+		semconv.DBQueryText(sql),
+		attribute.Int64("db.response.rows_affected", tx.RowsAffected),
+	)
+	// Lookups that find nothing are answered with a 404 rather than failing
+	if err := tx.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		query.span.RecordError(err)
+		query.span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func dbSystem() attribute.KeyValue {
+	if Driver() == DriverSQLite {
+		return semconv.DBSystemSqlite
+	}
+	return semconv.DBSystemPostgreSQL
+}
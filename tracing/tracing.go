@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"banter/utils/config"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOtlp   = "otlp"
+)
+
+const tracerName = "banter"
+
+var provider *sdktrace.TracerProvider
+
+// Setup installs the tracer provider of the configured exporter. With no exporter spans are not
+// recorded, but trace context sent by callers is still passed on so log lines can be correlated.
+func Setup(cfg config.TracingConfig) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var processor sdktrace.SpanProcessor
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return err
+		}
+		// Spans are printed as they end, which is what you want when debugging locally
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	case ExporterOtlp:
+		exporter, err := newOtlpExporter(cfg.Otlp)
+		if err != nil {
+			return err
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	default:
+		return fmt.Errorf("unsupported tracing exporter %q", cfg.Exporter)
+	}
+
+	serviceResource, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return err
+	}
+
+	// Callers that already decided whether to sample a trace keep their decision
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(cfg.SamplePercent) / 100))
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sampler),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+func newOtlpExporter(cfg config.OtlpConfig) (sdktrace.SpanExporter, error) {
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		headers := make(map[string]string, len(cfg.Headers))
+		for _, header := range cfg.Headers {
+			name, value, _ := strings.Cut(header, "=")
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		options = append(options, otlptracehttp.WithHeaders(headers))
+	}
+	// The exporter connects lazily, so a collector that is down doesn't stop the server from starting
+	return otlptracehttp.New(context.Background(), options...)
+}
+
+// Shutdown exports the spans still buffered and stops the tracer provider
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// Tracer returns the tracer spans of the application are started with
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Middleware starts a span for every request, continuing the trace of the caller when it sent a
+// traceparent header. The span is named after the route template rather than the path, so requests
+// to the same endpoint are grouped together.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
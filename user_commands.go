@@ -0,0 +1,226 @@
+package main
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/repositories"
+	"banter/stores"
+	"banter/utils/logger"
+	"banter/workers"
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cliUserAgent identifies audit entries written from the command line
+const cliUserAgent = "banter-cli"
+
+// runCreateAdminCommand creates an owner account, which is how the first administrator is bootstrapped
+func runCreateAdminCommand(args []string) {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	username := flags.String("username", "", "username of the new account")
+	email := flags.String("email", "", "email of the new account")
+	password := flags.String("password", "", "password of the new account, read from stdin when empty")
+	firstName := flags.String("first-name", "", "first name of the new account")
+	lastName := flags.String("last-name", "", "last name of the new account")
+	flags.Parse(args)
+
+	if *username == "" || *email == "" {
+		fmt.Fprintln(os.Stderr, "Usage: banter create-admin -username <username> -email <email> [-password <password>] [-first-name <name>] [-last-name <name>]")
+		os.Exit(2)
+	}
+
+	store := repositories.NewGormStore(stores.GetDb())
+	if _, err := store.Users().GetByEmailOrUsername(context.Background(), *email, *username); err == nil {
+		logger.Logger.Fatalf("A user with username %q or email %q already exists", *username, *email)
+	}
+
+	hashedPassword := hashPassword(readPassword(*password))
+	user := models.User{
+		ID:        uuid.New(),
+		Username:  *username,
+		Email:     *email,
+		Password:  hashedPassword,
+		FirstName: *firstName,
+		LastName:  *lastName,
+		IsStaff:   true,
+		IsOwner:   true,
+		Status:    enums.UserActive,
+	}
+	if err := store.Users().Create(context.Background(), &user); err != nil {
+		logger.Logger.Fatalf("Failed to create user: %v", err)
+	}
+
+	recordCommandAudit(store, enums.AuditUserCreatedAdmin, "user", user.ID, nil, map[string]interface{}{"username": user.Username, "email": user.Email})
+	fmt.Printf("Created owner %s (%s)\n", user.Username, user.ID)
+}
+
+// runResetPasswordCommand sets a new password for a user and revokes every session they have
+func runResetPasswordCommand(args []string) {
+	flags := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	password := flags.String("password", "", "new password, read from stdin when empty")
+	require := flags.Bool("require-change", false, "make the user choose a new password after signing in")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: banter reset-password [-password <password>] [-require-change] <username or email>")
+		os.Exit(2)
+	}
+
+	store := repositories.NewGormStore(stores.GetDb())
+	user := commandUser(store, flags.Arg(0))
+	if user.IsBot() {
+		logger.Logger.Fatalf("%s is a bot account and has no password", user.Username)
+	}
+
+	user.Password = hashPassword(readPassword(*password))
+	user.PasswordResetRequired = *require
+	user.RevokeSessions()
+	if err := store.Users().Update(context.Background(), user); err != nil {
+		logger.Logger.Fatalf("Failed to update user: %v", err)
+	}
+
+	recordCommandAudit(store, enums.AuditUserPasswordReset, "user", user.ID, nil, nil)
+	fmt.Printf("Password of %s reset, existing sessions were revoked\n", user.Username)
+}
+
+// runBanUserCommand bans a user, permanently unless a duration is given
+func runBanUserCommand(args []string) {
+	flags := flag.NewFlagSet("ban-user", flag.ExitOnError)
+	reason := flags.String("reason", "", "reason shown in the audit log")
+	duration := flags.Duration("duration", 0, "how long the ban lasts, e.g. 72h, permanent when 0")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *duration < 0 {
+		fmt.Fprintln(os.Stderr, "Usage: banter ban-user [-reason <reason>] [-duration <duration>] <username or email>")
+		os.Exit(2)
+	}
+
+	store := repositories.NewGormStore(stores.GetDb())
+	user := commandUser(store, flags.Arg(0))
+
+	var expiresAt *time.Time
+	if *duration > 0 {
+		until := time.Now().Add(*duration)
+		expiresAt = &until
+	}
+
+	before := commandUserStatus(user)
+	user.SetStatus(enums.UserBanned, *reason, expiresAt)
+	user.RevokeSessions()
+	if err := store.Users().Update(context.Background(), user); err != nil {
+		logger.Logger.Fatalf("Failed to update user: %v", err)
+	}
+
+	recordCommandAudit(store, enums.AuditUserBanned, "user", user.ID, before, commandUserStatus(user))
+	if expiresAt != nil {
+		fmt.Printf("Banned %s until %s\n", user.Username, expiresAt.Format(time.RFC3339))
+	} else {
+		fmt.Printf("Banned %s\n", user.Username)
+	}
+}
+
+// runExportUserCommand builds a data export for a user right away instead of queueing it for the worker
+func runExportUserCommand(args []string) {
+	flags := flag.NewFlagSet("export-user", flag.ExitOnError)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: banter export-user <username or email>")
+		os.Exit(2)
+	}
+
+	store := repositories.NewGormStore(stores.GetDb())
+	user := commandUser(store, flags.Arg(0))
+
+	ctx := context.Background()
+	export := models.DataExport{UserID: user.ID}
+	if err := store.DataExports().Create(ctx, &export); err != nil {
+		logger.Logger.Fatalf("Failed to create export: %v", err)
+	}
+	recordCommandAudit(store, enums.AuditAccountExportRequest, "data_export", export.ID, nil, nil)
+
+	workers.ProcessDataExport(ctx, store, export.ID)
+
+	processed, err := store.DataExports().GetByID(ctx, export.ID)
+	if err != nil {
+		logger.Logger.Fatalf("Failed to load export: %v", err)
+	}
+	if processed.Status != enums.ExportCompleted {
+		logger.Logger.Fatalf("Export %s is %s: %s", processed.ID, processed.Status, processed.Error)
+	}
+	fmt.Println(processed.FilePath)
+}
+
+// commandUser fetches the user with the given username or email and exits when there is none
+func commandUser(store repositories.Store, identifier string) *models.User {
+	user, err := store.Users().GetByEmailOrUsername(context.Background(), identifier, identifier)
+	if err != nil {
+		logger.Logger.Fatalf("No user found with username or email %q", identifier)
+	}
+	return user
+}
+
+// commandUserStatus mirrors the status details the admin API records in the audit log
+func commandUserStatus(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"status":            user.Status,
+		"status_reason":     user.StatusReason,
+		"status_expires_at": user.StatusExpiresAt,
+	}
+}
+
+// readPassword returns the given password or reads one line from stdin, and checks its length
+func readPassword(password string) string {
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			logger.Logger.Fatalf("Failed to read password: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	// Same bounds the API enforces on registration
+	if len(password) < 8 || len(password) > 32 {
+		logger.Logger.Fatalf("Password must be between 8 and 32 characters")
+	}
+	return password
+}
+
+func hashPassword(password string) string {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Logger.Fatalf("Failed to hash password: %v", err)
+	}
+	return string(hashedPassword)
+}
+
+// recordCommandAudit writes an audit entry without an actor, as commands run with direct database access.
+// Failing to write the entry doesn't undo the command.
+func recordCommandAudit(store repositories.Store, action enums.AuditAction, targetType string, targetID uuid.UUID, before, after map[string]interface{}) {
+	entry := models.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID.String(),
+		UserAgent:  cliUserAgent,
+	}
+	if before != nil {
+		entry.Before, _ = json.Marshal(before)
+	}
+	if after != nil {
+		entry.After, _ = json.Marshal(after)
+	}
+
+	if err := store.AuditLogs().Create(context.Background(), &entry); err != nil {
+		logger.Logger.Printf("Failed to write audit log for %s: %v", action, err)
+	}
+}
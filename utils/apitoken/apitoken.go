@@ -0,0 +1,34 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix marks bot API tokens so they can be told apart from user JWTs
+const Prefix = "bnt_"
+
+// Generate creates a new random API token and returns it along with its hash and a short
+// display prefix. Only the hash and display prefix should be stored.
+func Generate() (token string, hash string, displayPrefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	token = Prefix + hex.EncodeToString(secret)
+	return token, Hash(token), token[:len(Prefix)+8], nil
+}
+
+// Hash returns the hash an API token is stored under
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsApiToken reports whether the bearer token is an API token rather than a JWT
+func IsApiToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
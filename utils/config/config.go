@@ -1,40 +1,267 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"gopkg.in/yaml.v2"
 )
 
-// This struct reflects the yaml file structure
+// DefaultPath is read when no config file is given. Unlike an explicitly given file it may be missing,
+// in which case the defaults and environment variables are used on their own.
+const DefaultPath = "config.yaml"
+
+// Config reflects the yaml file structure. Every setting can be overridden by an environment variable
+// named after its yaml path, see ApplyEnvironment.
 type Config struct {
-	Server struct {
-		Port string `yaml:"port"`
-		Mode string `yaml:"mode"`
+	Server        ServerConfig        `yaml:"server"`
+	Cors          CorsConfig          `yaml:"cors"`
+	Security      SecurityConfig      `yaml:"security"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	Jwt           JwtConfig           `yaml:"jwt"`
+	Stores        StoresConfig        `yaml:"stores"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Accounts      AccountsConfig      `yaml:"accounts"`
+	Exports       ExportsConfig       `yaml:"exports"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Webhooks      WebhooksConfig      `yaml:"webhooks"`
+	Bots          BotsConfig          `yaml:"bots"`
+	Events        EventsConfig        `yaml:"events"`
+	Metrics       MetricsConfig       `yaml:"metrics"`
+	Tracing       TracingConfig       `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Port                  string   `yaml:"port"`
+	Mode                  string   `yaml:"mode"`
+	ReadTimeoutInSecs     int      `yaml:"read_timeout_in_secs"`
+	WriteTimeoutInSecs    int      `yaml:"write_timeout_in_secs"`
+	IdleTimeoutInSecs     int      `yaml:"idle_timeout_in_secs"`
+	ShutdownTimeoutInSecs int      `yaml:"shutdown_timeout_in_secs"`
+	TrustedProxies        []string `yaml:"trusted_proxies"`
+}
+
+type CorsConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	ExposedHeaders   []string `yaml:"exposed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	MaxAgeInSecs     int      `yaml:"max_age_in_secs"`
+}
+
+type SecurityConfig struct {
+	HstsMaxAgeInSecs       int      `yaml:"hsts_max_age_in_secs"`
+	HstsIncludeSubdomains  bool     `yaml:"hsts_include_subdomains"`
+	FrameAncestors         []string `yaml:"frame_ancestors"`
+	MaxBodySizeInKb        int      `yaml:"max_body_size_in_kb"`
+	MaxMessageBodySizeInKb int      `yaml:"max_message_body_size_in_kb"`
+	MaxJsonDepth           int      `yaml:"max_json_depth"`
+}
+
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+type JwtConfig struct {
+	Secret string `yaml:"secret"`
+}
+
+type StoresConfig struct {
+	Driver                 string         `yaml:"driver"`
+	MigrateOnStart         bool           `yaml:"migrate_on_start"`
+	SlowQueryThresholdInMs int            `yaml:"slow_query_threshold_in_ms"`
+	QueryTimeoutInMs       int            `yaml:"query_timeout_in_ms"`
+	Postgres               PostgresConfig `yaml:"postgres"`
+	Sqlite                 SqliteConfig   `yaml:"sqlite"`
+}
+
+type PostgresConfig struct {
+	ConnectionString string `yaml:"connection_string"`
+}
+
+type SqliteConfig struct {
+	Path string `yaml:"path"`
+}
+
+type AuthConfig struct {
+	TokenValidityInHrs int `yaml:"token_validity_in_hrs"`
+}
+
+type AccountsConfig struct {
+	DeletionGracePeriodInDays int `yaml:"deletion_grace_period_in_days"`
+}
+
+type ExportsConfig struct {
+	Directory     string `yaml:"directory"`
+	ValidityInHrs int    `yaml:"validity_in_hrs"`
+}
+
+type NotificationsConfig struct {
+	Provider           string     `yaml:"provider"`
+	Workers            int        `yaml:"workers"`
+	QueueSize          int        `yaml:"queue_size"`
+	MaxRetries         int        `yaml:"max_retries"`
+	OnlineWindowInSecs int        `yaml:"online_window_in_secs"`
+	Fcm                FcmConfig  `yaml:"fcm"`
+	Apns               ApnsConfig `yaml:"apns"`
+}
+
+type FcmConfig struct {
+	CredentialsFile string `yaml:"credentials_file"`
+}
+
+type ApnsConfig struct {
+	KeyFile    string `yaml:"key_file"`
+	KeyID      string `yaml:"key_id"`
+	TeamID     string `yaml:"team_id"`
+	BundleID   string `yaml:"bundle_id"`
+	Production bool   `yaml:"production"`
+}
+
+type WebhooksConfig struct {
+	MaxAttempts   int `yaml:"max_attempts"`
+	TimeoutInSecs int `yaml:"timeout_in_secs"`
+}
+
+type BotsConfig struct {
+	RateLimitPerMinute   int `yaml:"rate_limit_per_minute"`
+	CommandTimeoutInSecs int `yaml:"command_timeout_in_secs"`
+}
+
+type EventsConfig struct {
+	Broker            string     `yaml:"broker"`
+	RelayIntervalInMs int        `yaml:"relay_interval_in_ms"`
+	RelayBatchSize    int        `yaml:"relay_batch_size"`
+	RetentionInDays   int        `yaml:"retention_in_days"`
+	Nats              NatsConfig `yaml:"nats"`
+}
+
+type NatsConfig struct {
+	Url           string `yaml:"url"`
+	SubjectPrefix string `yaml:"subject_prefix"`
+	Stream        string `yaml:"stream"`
+	MaxDeliver    int    `yaml:"max_deliver"`
+}
+
+type MetricsConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	BearerToken string `yaml:"bearer_token"`
+}
+
+type TracingConfig struct {
+	Exporter      string     `yaml:"exporter"`
+	ServiceName   string     `yaml:"service_name"`
+	SamplePercent int        `yaml:"sample_percent"`
+	Otlp          OtlpConfig `yaml:"otlp"`
+}
+
+type OtlpConfig struct {
+	Endpoint string   `yaml:"endpoint"`
+	Insecure bool     `yaml:"insecure"`
+	Headers  []string `yaml:"headers"`
+}
+
+var Configs Config
+
+// Defaults returns the settings used for anything the config file and environment leave out
+func Defaults() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:                  ":9090",
+			Mode:                  "debug",
+			ReadTimeoutInSecs:     30,
+			WriteTimeoutInSecs:    120,
+			IdleTimeoutInSecs:     120,
+			ShutdownTimeoutInSecs: 30,
+			TrustedProxies:        []string{"127.0.0.1", "::1"},
+		},
+		Cors: CorsConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-Request-ID"},
+			ExposedHeaders: []string{"X-Request-ID", "Content-Language"},
+			MaxAgeInSecs:   600,
+		},
+		Security: SecurityConfig{
+			HstsMaxAgeInSecs:       31536000,
+			HstsIncludeSubdomains:  true,
+			MaxBodySizeInKb:        64,
+			MaxMessageBodySizeInKb: 512,
+			MaxJsonDepth:           32,
+		},
+		Logging: LoggingConfig{Level: "info"},
+		Stores: StoresConfig{
+			Driver:                 "postgres",
+			SlowQueryThresholdInMs: 200,
+			QueryTimeoutInMs:       5000,
+			Sqlite:                 SqliteConfig{Path: "banter.db"},
+		},
+		Auth:     AuthConfig{TokenValidityInHrs: 1},
+		Accounts: AccountsConfig{DeletionGracePeriodInDays: 30},
+		Exports:  ExportsConfig{Directory: "exports", ValidityInHrs: 72},
+		Notifications: NotificationsConfig{
+			Provider:           "push",
+			Workers:            4,
+			QueueSize:          1000,
+			MaxRetries:         5,
+			OnlineWindowInSecs: 60,
+		},
+		Webhooks: WebhooksConfig{MaxAttempts: 8, TimeoutInSecs: 10},
+		Bots:     BotsConfig{RateLimitPerMinute: 60, CommandTimeoutInSecs: 5},
+		Events: EventsConfig{
+			Broker:            "memory",
+			RelayIntervalInMs: 500,
+			RelayBatchSize:    100,
+			RetentionInDays:   7,
+			Nats:              NatsConfig{Url: "nats://127.0.0.1:4222", SubjectPrefix: "banter.events", Stream: "BANTER_EVENTS", MaxDeliver: 5},
+		},
+		Metrics: MetricsConfig{Enabled: true},
+		Tracing: TracingConfig{
+			Exporter:      "none",
+			ServiceName:   "banter",
+			SamplePercent: 100,
+			Otlp:          OtlpConfig{Endpoint: "127.0.0.1:4318"},
+		},
 	}
-	Jwt struct {
-		Secret string `yaml:"secret"`
+}
+
+// Load builds the configuration from the defaults, the yaml file at path and the environment, in that
+// order, and validates the result. An empty path reads DefaultPath if it exists.
+func Load(path string) (Config, error) {
+	cfg := Defaults()
+
+	required := path != ""
+	if !required {
+		path = DefaultPath
 	}
-	Stores struct {
-		Postgres struct {
-			ConnectionString string `yaml:"connection_string"`
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse %s: %w", path, err)
 		}
+	case required || !errors.Is(err, os.ErrNotExist):
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
 	}
-	Auth struct {
-		TokenValidityInHrs int `yaml:"token_validity_in_hrs"`
-	}
-}
 
-var Configs Config
+	if err := ApplyEnvironment(&cfg, os.LookupEnv); err != nil {
+		return cfg, err
+	}
 
-func LoadConfig() {
-	data, err := os.ReadFile("config.yaml")
-	if err != nil {
-		panic(err)
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
 	}
+	return cfg, nil
+}
 
-	err = yaml.Unmarshal(data, &Configs)
+// LoadConfig loads the configuration into Configs
+func LoadConfig(path string) error {
+	cfg, err := Load(path)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	Configs = cfg
+	return nil
 }
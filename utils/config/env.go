@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the name of every environment variable that overrides a setting
+const EnvPrefix = "BANTER"
+
+// ApplyEnvironment overrides settings with environment variables named after their yaml path, e.g.
+// BANTER_JWT_SECRET for jwt.secret or BANTER_STORES_POSTGRES_CONNECTION_STRING for
+// stores.postgres.connection_string. Appending _FILE to the name reads the value from that file
+// instead, which is how container orchestrators hand out secrets. Lists are comma separated.
+func ApplyEnvironment(cfg *Config, lookup func(string) (string, bool)) error {
+	return applyEnvironment(reflect.ValueOf(cfg).Elem(), EnvPrefix, lookup)
+}
+
+func applyEnvironment(value reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + "_" + strings.ToUpper(tag)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnvironment(value.Field(i), name, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, found, err := lookupSetting(name, lookup)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if err := setSetting(value.Field(i), raw); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// lookupSetting reads the variable itself, or the file named by its _FILE variant
+func lookupSetting(name string, lookup func(string) (string, bool)) (string, bool, error) {
+	if raw, found := lookup(name); found {
+		return raw, true, nil
+	}
+
+	path, found := lookup(name + "_FILE")
+	if !found || path == "" {
+		return "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	// Secret files usually end with a newline that isn't part of the value
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+func setSetting(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		number, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		field.SetInt(int64(number))
+	case reflect.Bool:
+		enabled, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		field.SetBool(enabled)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list of %s", field.Type().Elem().Kind())
+		}
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Kind())
+	}
+	return nil
+}
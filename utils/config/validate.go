@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// minJwtSecretLength keeps tokens from being signed with a guessable secret
+const minJwtSecretLength = 32
+
+// ValidationError lists every problem found in a configuration, so they can all be fixed in one go
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate reports every setting that would stop the server from starting or working correctly
+func (c *Config) Validate() error {
+	v := validator{}
+
+	v.require(c.Server.Port != "", "server.port is required")
+	v.oneOf("server.mode", c.Server.Mode, "debug", "release")
+	v.notNegative("server.read_timeout_in_secs", c.Server.ReadTimeoutInSecs)
+	v.notNegative("server.write_timeout_in_secs", c.Server.WriteTimeoutInSecs)
+	v.notNegative("server.idle_timeout_in_secs", c.Server.IdleTimeoutInSecs)
+	v.positive("server.shutdown_timeout_in_secs", c.Server.ShutdownTimeoutInSecs)
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		v.require(net.ParseIP(proxy) != nil || cidrErr == nil,
+			fmt.Sprintf("server.trusted_proxies must be IP addresses or CIDR ranges, got %q", proxy))
+	}
+
+	for _, origin := range c.Cors.AllowedOrigins {
+		v.require(validOrigin(origin),
+			fmt.Sprintf("cors.allowed_origins must be * or scheme://host[:port] origins, optionally with a *. subdomain wildcard, got %q", origin))
+	}
+	v.require(!c.Cors.AllowCredentials || !slices.Contains(c.Cors.AllowedOrigins, "*"),
+		"cors.allow_credentials cannot be used with the * origin, list the allowed origins instead")
+	for _, method := range c.Cors.AllowedMethods {
+		v.oneOf("cors.allowed_methods", method, http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete)
+	}
+	v.notNegative("cors.max_age_in_secs", c.Cors.MaxAgeInSecs)
+
+	v.notNegative("security.hsts_max_age_in_secs", c.Security.HstsMaxAgeInSecs)
+	for _, ancestor := range c.Security.FrameAncestors {
+		v.require(ancestor == "'self'" || validOrigin(ancestor),
+			fmt.Sprintf("security.frame_ancestors must be 'self' or origins, got %q", ancestor))
+	}
+	v.notNegative("security.max_body_size_in_kb", c.Security.MaxBodySizeInKb)
+	v.notNegative("security.max_message_body_size_in_kb", c.Security.MaxMessageBodySizeInKb)
+	v.positive("security.max_json_depth", c.Security.MaxJsonDepth)
+
+	v.oneOf("logging.level", strings.ToLower(c.Logging.Level), "debug", "info", "warn", "error")
+	v.oneOf("logging.format", strings.ToLower(c.Logging.Format), "", "json", "text")
+
+	v.require(c.Jwt.Secret != "", "jwt.secret is required, set it in the config file or BANTER_JWT_SECRET")
+	v.require(c.Jwt.Secret == "" || len(c.Jwt.Secret) >= minJwtSecretLength,
+		fmt.Sprintf("jwt.secret must be at least %d characters", minJwtSecretLength))
+
+	v.oneOf("stores.driver", c.Stores.Driver, "postgres", "sqlite")
+	if c.Stores.Driver == "postgres" {
+		v.require(c.Stores.Postgres.ConnectionString != "",
+			"stores.postgres.connection_string is required for the postgres driver, set it in the config file or BANTER_STORES_POSTGRES_CONNECTION_STRING")
+	}
+	if c.Stores.Driver == "sqlite" {
+		v.require(c.Stores.Sqlite.Path != "", "stores.sqlite.path is required for the sqlite driver")
+	}
+
+	v.notNegative("stores.slow_query_threshold_in_ms", c.Stores.SlowQueryThresholdInMs)
+	v.notNegative("stores.query_timeout_in_ms", c.Stores.QueryTimeoutInMs)
+
+	v.positive("auth.token_validity_in_hrs", c.Auth.TokenValidityInHrs)
+	v.notNegative("accounts.deletion_grace_period_in_days", c.Accounts.DeletionGracePeriodInDays)
+
+	v.require(c.Exports.Directory != "", "exports.directory is required")
+	v.positive("exports.validity_in_hrs", c.Exports.ValidityInHrs)
+
+	v.oneOf("notifications.provider", c.Notifications.Provider, "push", "fake")
+	v.positive("notifications.workers", c.Notifications.Workers)
+	v.positive("notifications.queue_size", c.Notifications.QueueSize)
+	v.notNegative("notifications.max_retries", c.Notifications.MaxRetries)
+	v.notNegative("notifications.online_window_in_secs", c.Notifications.OnlineWindowInSecs)
+	if apns := c.Notifications.Apns; apns.KeyFile != "" {
+		v.require(apns.KeyID != "" && apns.TeamID != "" && apns.BundleID != "",
+			"notifications.apns.key_id, team_id and bundle_id are required when key_file is set")
+	}
+
+	v.positive("webhooks.max_attempts", c.Webhooks.MaxAttempts)
+	v.positive("webhooks.timeout_in_secs", c.Webhooks.TimeoutInSecs)
+
+	v.positive("bots.rate_limit_per_minute", c.Bots.RateLimitPerMinute)
+	v.positive("bots.command_timeout_in_secs", c.Bots.CommandTimeoutInSecs)
+
+	v.oneOf("events.broker", c.Events.Broker, "memory", "nats")
+	if c.Events.Broker == "nats" {
+		v.require(c.Events.Nats.Url != "", "events.nats.url is required for the nats broker")
+		v.require(c.Events.Nats.Stream != "" && !strings.ContainsAny(c.Events.Nats.Stream, " .*>/\\"),
+			"events.nats.stream is required for the nats broker and may not contain spaces, dots, wildcards or slashes")
+		v.positive("events.nats.max_deliver", c.Events.Nats.MaxDeliver)
+	}
+	v.positive("events.relay_interval_in_ms", c.Events.RelayIntervalInMs)
+	v.positive("events.relay_batch_size", c.Events.RelayBatchSize)
+	v.positive("events.retention_in_days", c.Events.RetentionInDays)
+
+	// Metrics reveal traffic and queue sizes, in release mode they may only be served to scrapers with the token
+	v.require(!c.Metrics.Enabled || c.Server.Mode != "release" || c.Metrics.BearerToken != "",
+		"metrics.bearer_token is required in release mode while metrics are enabled, set BANTER_METRICS_BEARER_TOKEN or disable metrics")
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "none", "stdout", "otlp")
+	v.require(c.Tracing.ServiceName != "", "tracing.service_name is required")
+	v.require(c.Tracing.SamplePercent >= 0 && c.Tracing.SamplePercent <= 100,
+		fmt.Sprintf("tracing.sample_percent must be between 0 and 100, got %d", c.Tracing.SamplePercent))
+	if c.Tracing.Exporter == "otlp" {
+		v.require(c.Tracing.Otlp.Endpoint != "", "tracing.otlp.endpoint is required for the otlp exporter")
+	}
+	for _, header := range c.Tracing.Otlp.Headers {
+		name, _, found := strings.Cut(header, "=")
+		v.require(found && strings.TrimSpace(name) != "", fmt.Sprintf("tracing.otlp.headers must be name=value pairs, got %q", header))
+	}
+
+	return v.err()
+}
+
+// validOrigin tells whether an allowed CORS origin is *, or an origin such as https://example.com,
+// http://127.0.0.1:3000 or https://*.example.com
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	parsed, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" && parsed.Path == "" &&
+		parsed.RawQuery == "" && parsed.Fragment == "" && parsed.User == nil && !strings.Contains(parsed.Host, "*")
+}
+
+// validator collects problems instead of stopping at the first one
+type validator struct {
+	problems []string
+}
+
+func (v *validator) require(ok bool, problem string) {
+	if !ok {
+		v.problems = append(v.problems, problem)
+	}
+}
+
+func (v *validator) positive(key string, value int) {
+	v.require(value > 0, fmt.Sprintf("%s must be greater than 0, got %d", key, value))
+}
+
+func (v *validator) notNegative(key string, value int) {
+	v.require(value >= 0, fmt.Sprintf("%s must not be negative, got %d", key, value))
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, option := range allowed {
+		if value == option {
+			return
+		}
+	}
+	v.problems = append(v.problems, fmt.Sprintf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value))
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
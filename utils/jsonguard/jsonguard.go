@@ -0,0 +1,64 @@
+package jsonguard
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTooDeep is returned when a JSON body nests objects and arrays deeper than allowed
+var ErrTooDeep = errors.New("json body is nested too deeply")
+
+// depthReader passes a body through while tracking how deeply the JSON read so far is nested
+type depthReader struct {
+	io.ReadCloser
+	maxDepth int
+
+	depth             int
+	inString, escaped bool
+	err               error
+}
+
+// NewReader returns a reader over body that fails with ErrTooDeep as soon as the JSON in it nests
+// objects and arrays deeper than maxDepth. It only counts brackets outside of strings and leaves
+// reporting malformed JSON to the decoder.
+func NewReader(body io.ReadCloser, maxDepth int) io.ReadCloser {
+	return &depthReader{ReadCloser: body, maxDepth: maxDepth}
+}
+
+func (r *depthReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.ReadCloser.Read(p)
+	for _, b := range p[:n] {
+		if !r.scan(b) {
+			// Hold back the whole chunk so the decoder can't finish a value with what it holds already
+			r.err = ErrTooDeep
+			return 0, r.err
+		}
+	}
+	return n, err
+}
+
+// scan tracks the nesting after byte b and reports whether it is still within the limit
+func (r *depthReader) scan(b byte) bool {
+	switch {
+	case r.escaped:
+		r.escaped = false
+	case r.inString:
+		switch b {
+		case '\\':
+			r.escaped = true
+		case '"':
+			r.inString = false
+		}
+	case b == '"':
+		r.inString = true
+	case b == '{' || b == '[':
+		r.depth++
+		return r.depth <= r.maxDepth
+	case b == '}' || b == ']':
+		r.depth--
+	}
+	return true
+}
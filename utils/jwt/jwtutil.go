@@ -1,15 +1,19 @@
 package jwt
 
 import (
-	"fmt"
 	"banter/utils/config"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateToken generates a new JWT token with the provided claims
-func GenerateToken(user_id string, expiration int) (string, error) {
+// SessionVersionClaim holds the session version of the user a token was issued for
+const SessionVersionClaim = "session_version"
+
+// GenerateToken generates a new JWT token with the provided claims. Pass the user's current session
+// version, revoking the user's sessions invalidates the token.
+func GenerateToken(user_id string, sessionVersion int, expiration int) (string, error) {
 	// Ensure the secret key is available
 	secretKey := config.Configs.Jwt.Secret
 	if secretKey == "" {
@@ -21,9 +25,12 @@ func GenerateToken(user_id string, expiration int) (string, error) {
 	claims := token.Claims.(jwt.MapClaims)
 
 	// Set expiration time for the token
+	now := time.Now()
 	expiry := time.Hour * time.Duration(expiration)
 	claims["user_id"] = user_id
-	claims["exp"] = time.Now().Add(expiry).Unix()
+	claims[SessionVersionClaim] = sessionVersion
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(expiry).Unix()
 
 	// Sign the token with the secret key
 	tokenString, err := token.SignedString([]byte(secretKey))
@@ -1,12 +1,102 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Logger writes printf style lines through the structured logger, for code that has no fields to add
+// such as the command line tools
 var Logger *log.Logger
 
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+)
+
+// SetupLogger logs human readable lines at info level until Configure is called with the settings
+// from the config file
 func SetupLogger() {
-	Logger = log.New(os.Stdout, "banter: ", log.LstdFlags|log.Lshortfile)
+	install(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{AddSource: true}))
+}
+
+// Configure switches the logger to the given format and minimum level. An empty format logs JSON in
+// release mode and text otherwise.
+func Configure(format, level, mode string) error {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	options := &slog.HandlerOptions{AddSource: true, Level: minLevel}
+
+	if format == "" && mode == "release" {
+		format = "json"
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		install(slog.NewJSONHandler(os.Stdout, options))
+	case "", "text":
+		install(slog.NewTextHandler(os.Stdout, options))
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+	return nil
+}
+
+func install(handler slog.Handler) {
+	handler = &contextHandler{Handler: handler}
+	slog.SetDefault(slog.New(handler))
+	Logger = slog.NewLogLogger(handler, slog.LevelInfo)
+}
+
+// WithRequestID returns a context whose log lines carry the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the request the context belongs to, if any
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithUserID returns a context whose log lines carry the ID of the authenticated user
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// contextHandler adds the request and user IDs and the trace found in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestID := RequestID(ctx); requestID != "" {
+			record.AddAttrs(slog.String("request_id", requestID))
+		}
+		if userID, _ := ctx.Value(userIDKey).(string); userID != "" {
+			record.AddAttrs(slog.String("user_id", userID))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
 }
@@ -1,26 +1,295 @@
 package migrations
 
 import (
-	"banter/models"
-	"banter/stores"
-	"banter/utils/logger"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-func RegisterAllModels() {
-	modelTypes := []interface{}{
-		&models.User{},
-		&models.Conversation{},
-		&models.ConversationMember{},
-		&models.Message{},
-		&models.Attachment{},
+// SourceDirectory is where the migration files live in the source tree, new ones are created here
+const SourceDirectory = "utils/migrations/sql"
+
+// advisoryLockID identifies the Postgres advisory lock held while migrating
+const advisoryLockID = 7268305135
+
+//go:embed sql
+var files embed.FS
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied and when
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row of the schema_migrations table
+type schemaMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Load reads the migrations of a driver embedded in the binary, oldest first
+func Load(driver string) ([]Migration, error) {
+	dir := "sql/" + driver
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %s", driver)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		data, err := fs.ReadFile(files, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has files named both %s and %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
 
-		// add new models here for migration
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
 	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies pending migrations in order, all of them when steps is 0. It returns the migrations
+// that were applied.
+func Up(db *gorm.DB, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := withLock(db, func() error {
+		statuses, err := status(db)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
 
-	// Iterate through all models registered in the AllModels slice and auto-migrate them
-	for _, model := range modelTypes {
-		if err := stores.GetDb().AutoMigrate(model); err != nil {
-			logger.Logger.Fatalf("Failed to auto-migrate model %v: %v", model, err)
+			migration := s.Migration
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Table("schema_migrations").Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
 		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the given number of applied migrations, newest first. It returns the migrations
+// that were reverted.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := withLock(db, func() error {
+		statuses, err := status(db)
+		if err != nil {
+			return err
+		}
+
+		for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+			if statuses[i].AppliedAt == nil {
+				continue
+			}
+
+			migration := statuses[i].Migration
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration and whether it has been applied. It only reads, so it is safe to
+// call from probes.
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	return status(db)
+}
+
+// Pending returns the migrations that haven't been applied yet
+func Pending(db *gorm.DB) ([]Migration, error) {
+	statuses, err := Status(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Create writes empty up and down files for a new migration for every driver and returns their paths
+func Create(directory, name string, now time.Time) ([]string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("migration name may only contain letters, digits and underscores")
+	}
+
+	drivers, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	version := now.UTC().Format("20060102150405")
+	var paths []string
+	for _, driver := range drivers {
+		if !driver.IsDir() {
+			continue
+		}
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(directory, driver.Name(), fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+			content := fmt.Sprintf("-- %s migration %s for %s\n", direction, name, driver.Name())
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// status combines the embedded migrations with the applied ones recorded in schema_migrations
+func status(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// Before the first migration the table doesn't exist and nothing has been applied
+	var rows []schemaMigration
+	if db.Migrator().HasTable("schema_migrations") {
+		if err := db.Table("schema_migrations").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+	appliedAt := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		appliedAt[row.Version] = row.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		s := MigrationStatus{Migration: migration}
+		if at, ok := appliedAt[migration.Version]; ok {
+			s.AppliedAt = &at
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// ensureTable creates the table recording applied migrations
+func ensureTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    applied_at timestamp NOT NULL
+)`).Error
+}
+
+// withLock runs fn while holding the migration lock so replicas starting together don't migrate
+// twice. Postgres uses a session advisory lock on a dedicated connection. SQLite databases are a
+// local file used by a single instance, so no lock is taken there.
+func withLock(db *gorm.DB, fn func() error) error {
+	if db.Dialector.Name() == "postgres" {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", advisoryLockID)
+	}
+
+	if err := ensureTable(db); err != nil {
+		return err
 	}
+	return fn()
 }
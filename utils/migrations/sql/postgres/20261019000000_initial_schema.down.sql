@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS "processed_events";
+DROP TABLE IF EXISTS "outbox_events";
+DROP TABLE IF EXISTS "api_tokens";
+DROP TABLE IF EXISTS "bots";
+DROP TABLE IF EXISTS "webhook_deliveries";
+DROP TABLE IF EXISTS "webhook_subscriptions";
+DROP TABLE IF EXISTS "devices";
+DROP TABLE IF EXISTS "audit_logs";
+DROP TABLE IF EXISTS "data_exports";
+DROP TABLE IF EXISTS "attachments";
+DROP TABLE IF EXISTS "messages";
+DROP TABLE IF EXISTS "conversation_members";
+DROP TABLE IF EXISTS "conversations";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1 @@
+-- Nothing to revert, the columns belong to the initial schema
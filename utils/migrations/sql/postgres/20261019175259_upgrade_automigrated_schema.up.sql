@@ -0,0 +1,43 @@
+-- Databases set up by AutoMigrate before versioned migrations already had the users, conversations,
+-- conversation_members, messages and attachments tables, which the initial schema then skipped.
+-- This adds what those tables gained since. On databases the initial schema created it changes
+-- nothing.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "type" varchar(10) DEFAULT 'human';
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "status_reason" varchar(500);
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "status_expires_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "sessions_revoked_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "password_reset_required" boolean DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "deletion_scheduled_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_users_deletion_scheduled_at" ON "users"("deletion_scheduled_at");
+CREATE INDEX IF NOT EXISTS "idx_users_type" ON "users"("type");
+
+ALTER TABLE "conversations" ADD COLUMN IF NOT EXISTS "last_message_at" timestamptz DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX IF NOT EXISTS "idx_conversations_last_message_at" ON "conversations"("last_message_at");
+-- Conversations that just got the column would all look as recent as the upgrade
+UPDATE "conversations" SET "last_message_at" = COALESCE(
+    (SELECT MAX("messages"."created_at") FROM "messages" WHERE "messages"."conversation_id" = "conversations"."id"),
+    "conversations"."created_at"
+);
+
+ALTER TABLE "conversation_members" ADD COLUMN IF NOT EXISTS "is_pinned" boolean DEFAULT false;
+ALTER TABLE "conversation_members" ADD COLUMN IF NOT EXISTS "is_archived" boolean DEFAULT false;
+ALTER TABLE "conversation_members" ADD COLUMN IF NOT EXISTS "muted_until" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_conversation_members_is_archived" ON "conversation_members"("is_archived");
+CREATE INDEX IF NOT EXISTS "idx_conversation_members_is_pinned" ON "conversation_members"("is_pinned");
+CREATE INDEX IF NOT EXISTS "idx_conversation_members_muted_until" ON "conversation_members"("muted_until");
+
+-- Attachments referred to messages by a numeric id although messages are keyed by uuid, so none of
+-- those rows can belong to a message
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'attachments'
+            AND column_name = 'message_id' AND data_type = 'bigint'
+    ) THEN
+        DELETE FROM "attachments";
+        ALTER TABLE "attachments" ALTER COLUMN "message_id" TYPE uuid USING NULL;
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS "idx_attachments_message_id" ON "attachments"("message_id");
@@ -0,0 +1,2 @@
+-- Tokens carry the session version they were issued for, revoking sessions increments it
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "session_version" bigint NOT NULL DEFAULT 0;
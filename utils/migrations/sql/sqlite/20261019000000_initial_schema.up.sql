@@ -0,0 +1,274 @@
+-- Baseline schema
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` uuid,
+    `username` text NOT NULL,
+    `email` text NOT NULL,
+    `password` text NOT NULL,
+    `first_name` varchar(50),
+    `last_name` varchar(50),
+    `date_of_birth` date,
+    `gender` varchar(12),
+    `mobile_number` varchar(15),
+    `profile_photo_path` varchar(1024),
+    `profile_photo_url` varchar(1024),
+    `is_staff` numeric DEFAULT false,
+    `is_owner` numeric DEFAULT false,
+    `last_seen` timestamp,
+    `status` varchar(15),
+    `type` varchar(10) DEFAULT 'human',
+    `status_reason` varchar(500),
+    `status_expires_at` datetime,
+    `sessions_revoked_at` datetime,
+    `password_reset_required` numeric DEFAULT false,
+    `deletion_scheduled_at` datetime,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `deleted_at` datetime,
+    PRIMARY KEY (`id`),
+    CONSTRAINT `uni_users_username` UNIQUE (`username`),
+    CONSTRAINT `uni_users_email` UNIQUE (`email`)
+);
+CREATE INDEX IF NOT EXISTS `idx_users_created_at` ON `users`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_users_deleted_at` ON `users`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_users_deletion_scheduled_at` ON `users`(`deletion_scheduled_at`);
+CREATE INDEX IF NOT EXISTS `idx_users_email` ON `users`(`email`);
+CREATE INDEX IF NOT EXISTS `idx_users_is_owner` ON `users`(`is_owner`);
+CREATE INDEX IF NOT EXISTS `idx_users_is_staff` ON `users`(`is_staff`);
+CREATE INDEX IF NOT EXISTS `idx_users_last_seen` ON `users`(`last_seen`);
+CREATE INDEX IF NOT EXISTS `idx_users_mobile_number` ON `users`(`mobile_number`);
+CREATE INDEX IF NOT EXISTS `idx_users_status` ON `users`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_users_type` ON `users`(`type`);
+CREATE INDEX IF NOT EXISTS `idx_users_updated_at` ON `users`(`updated_at`);
+CREATE INDEX IF NOT EXISTS `idx_users_username` ON `users`(`username`);
+
+CREATE TABLE IF NOT EXISTS `conversations` (
+    `id` uuid,
+    `name` varchar(50),
+    `is_group` numeric DEFAULT false,
+    `group_photo_path` varchar(1024),
+    `group_photo_url` varchar(1024),
+    `last_message_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `deleted_at` datetime,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_conversations_created_at` ON `conversations`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_conversations_deleted_at` ON `conversations`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_conversations_is_group` ON `conversations`(`is_group`);
+CREATE INDEX IF NOT EXISTS `idx_conversations_last_message_at` ON `conversations`(`last_message_at`);
+CREATE INDEX IF NOT EXISTS `idx_conversations_updated_at` ON `conversations`(`updated_at`);
+
+CREATE TABLE IF NOT EXISTS `conversation_members` (
+    `id` uuid,
+    `conversation_id` uuid NOT NULL,
+    `member_id` uuid NOT NULL,
+    `is_pinned` numeric DEFAULT false,
+    `is_archived` numeric DEFAULT false,
+    `muted_until` datetime,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `deleted_at` datetime,
+    PRIMARY KEY (`id`),
+    CONSTRAINT `fk_conversation_members_conversation` FOREIGN KEY (`conversation_id`) REFERENCES `conversations`(`id`),
+    CONSTRAINT `fk_conversation_members_member` FOREIGN KEY (`member_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_conversation_id` ON `conversation_members`(`conversation_id`);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_created_at` ON `conversation_members`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_deleted_at` ON `conversation_members`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_is_archived` ON `conversation_members`(`is_archived`);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_is_pinned` ON `conversation_members`(`is_pinned`);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_member_id` ON `conversation_members`(`member_id`);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_muted_until` ON `conversation_members`(`muted_until`);
+CREATE INDEX IF NOT EXISTS `idx_conversation_members_updated_at` ON `conversation_members`(`updated_at`);
+
+CREATE TABLE IF NOT EXISTS `messages` (
+    `id` uuid,
+    `conversation_id` uuid NOT NULL,
+    `sender_id` uuid NOT NULL,
+    `content` varchar(65536),
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `deleted_at` datetime,
+    PRIMARY KEY (`id`),
+    CONSTRAINT `fk_messages_conversation` FOREIGN KEY (`conversation_id`) REFERENCES `conversations`(`id`),
+    CONSTRAINT `fk_messages_sender` FOREIGN KEY (`sender_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_messages_conversation_id` ON `messages`(`conversation_id`);
+CREATE INDEX IF NOT EXISTS `idx_messages_created_at` ON `messages`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_messages_deleted_at` ON `messages`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_messages_sender_id` ON `messages`(`sender_id`);
+CREATE INDEX IF NOT EXISTS `idx_messages_updated_at` ON `messages`(`updated_at`);
+
+CREATE TABLE IF NOT EXISTS `attachments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `message_id` uuid NOT NULL,
+    `file_path` varchar(2048),
+    `file_type` varchar(10),
+    `file_url` varchar(1024),
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `deleted_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_attachments_created_at` ON `attachments`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_attachments_deleted_at` ON `attachments`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_attachments_message_id` ON `attachments`(`message_id`);
+CREATE INDEX IF NOT EXISTS `idx_attachments_updated_at` ON `attachments`(`updated_at`);
+
+CREATE TABLE IF NOT EXISTS `data_exports` (
+    `id` uuid,
+    `user_id` uuid NOT NULL,
+    `status` varchar(15),
+    `file_path` varchar(2048),
+    `error` varchar(1024),
+    `completed_at` datetime,
+    `expires_at` datetime,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `deleted_at` datetime,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_created_at` ON `data_exports`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_deleted_at` ON `data_exports`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_expires_at` ON `data_exports`(`expires_at`);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_status` ON `data_exports`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_updated_at` ON `data_exports`(`updated_at`);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_user_id` ON `data_exports`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+    `id` uuid,
+    `actor_id` uuid,
+    `action` varchar(50) NOT NULL,
+    `target_type` varchar(50),
+    `target_id` varchar(64),
+    `ip_address` varchar(45),
+    `user_agent` varchar(512),
+    `before` text,
+    `after` text,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_action` ON `audit_logs`(`action`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_actor_id` ON `audit_logs`(`actor_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_created_at` ON `audit_logs`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_target_id` ON `audit_logs`(`target_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_target_type` ON `audit_logs`(`target_type`);
+
+CREATE TABLE IF NOT EXISTS `devices` (
+    `id` uuid,
+    `user_id` uuid NOT NULL,
+    `platform` varchar(10) NOT NULL,
+    `token` varchar(4096) NOT NULL,
+    `app_version` varchar(32),
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_devices_created_at` ON `devices`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_devices_updated_at` ON `devices`(`updated_at`);
+CREATE INDEX IF NOT EXISTS `idx_devices_user_id` ON `devices`(`user_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_devices_token` ON `devices`(`token`);
+
+CREATE TABLE IF NOT EXISTS `webhook_subscriptions` (
+    `id` uuid,
+    `url` varchar(2048) NOT NULL,
+    `secret` varchar(128) NOT NULL,
+    `events` varchar(1024) NOT NULL,
+    `is_active` numeric DEFAULT true,
+    `created_by_id` uuid,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `deleted_at` datetime,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_webhook_subscriptions_created_at` ON `webhook_subscriptions`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_subscriptions_created_by_id` ON `webhook_subscriptions`(`created_by_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_subscriptions_deleted_at` ON `webhook_subscriptions`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_subscriptions_is_active` ON `webhook_subscriptions`(`is_active`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_subscriptions_updated_at` ON `webhook_subscriptions`(`updated_at`);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+    `id` uuid,
+    `subscription_id` uuid NOT NULL,
+    `event_id` uuid NOT NULL,
+    `event` varchar(64) NOT NULL,
+    `payload` text NOT NULL,
+    `status` varchar(15) NOT NULL,
+    `attempts` integer DEFAULT 0,
+    `next_attempt_at` datetime,
+    `response_status` integer,
+    `last_error` varchar(1024),
+    `delivered_at` datetime,
+    `replay_of_id` uuid,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_created_at` ON `webhook_deliveries`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_event_id` ON `webhook_deliveries`(`event_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_event` ON `webhook_deliveries`(`event`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_next_attempt_at` ON `webhook_deliveries`(`next_attempt_at`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_status` ON `webhook_deliveries`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_subscription_id` ON `webhook_deliveries`(`subscription_id`);
+
+CREATE TABLE IF NOT EXISTS `bots` (
+    `user_id` uuid,
+    `owner_id` uuid NOT NULL,
+    `description` varchar(500),
+    `webhook_url` varchar(2048),
+    `webhook_secret` varchar(128) NOT NULL,
+    `commands` varchar(1024),
+    `rate_limit_per_minute` integer DEFAULT 0,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`),
+    CONSTRAINT `fk_bots_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_bots_created_at` ON `bots`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_bots_owner_id` ON `bots`(`owner_id`);
+CREATE INDEX IF NOT EXISTS `idx_bots_updated_at` ON `bots`(`updated_at`);
+
+CREATE TABLE IF NOT EXISTS `api_tokens` (
+    `id` uuid,
+    `user_id` uuid NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `prefix` varchar(16) NOT NULL,
+    `scopes` varchar(100) NOT NULL,
+    `expires_at` datetime,
+    `last_used_at` datetime,
+    `revoked_at` datetime,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_api_tokens_created_at` ON `api_tokens`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_api_tokens_revoked_at` ON `api_tokens`(`revoked_at`);
+CREATE INDEX IF NOT EXISTS `idx_api_tokens_user_id` ON `api_tokens`(`user_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_api_tokens_token_hash` ON `api_tokens`(`token_hash`);
+
+CREATE TABLE IF NOT EXISTS `outbox_events` (
+    `id` uuid,
+    `type` varchar(64) NOT NULL,
+    `aggregate_type` varchar(32) NOT NULL,
+    `aggregate_id` uuid NOT NULL,
+    `payload` text NOT NULL,
+    `attempts` integer DEFAULT 0,
+    `last_error` varchar(1024),
+    `next_attempt_at` datetime,
+    `published_at` datetime,
+    `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_outbox_events_aggregate_id` ON `outbox_events`(`aggregate_id`);
+CREATE INDEX IF NOT EXISTS `idx_outbox_events_created_at` ON `outbox_events`(`created_at`);
+CREATE INDEX IF NOT EXISTS `idx_outbox_events_next_attempt_at` ON `outbox_events`(`next_attempt_at`);
+CREATE INDEX IF NOT EXISTS `idx_outbox_events_published_at` ON `outbox_events`(`published_at`);
+CREATE INDEX IF NOT EXISTS `idx_outbox_events_type` ON `outbox_events`(`type`);
+
+CREATE TABLE IF NOT EXISTS `processed_events` (
+    `consumer` varchar(64),
+    `event_id` uuid,
+    `processed_at` datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`consumer`,`event_id`)
+);
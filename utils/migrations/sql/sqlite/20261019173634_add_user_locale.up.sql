@@ -0,0 +1,2 @@
+-- The language a user reads the API and notifications in, when unset Accept-Language decides
+ALTER TABLE `users` ADD COLUMN `locale` varchar(16);
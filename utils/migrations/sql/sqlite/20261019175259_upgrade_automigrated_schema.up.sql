@@ -0,0 +1,2 @@
+-- SQLite databases were first set up by AutoMigrate with every column of the initial schema, so
+-- there is nothing to upgrade, unlike on Postgres
@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/repositories"
+	"banter/utils/config"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is the envelope posted to webhook subscribers
+type Event struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      enums.EventType `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      interface{}     `json:"data"`
+}
+
+// PublishEvent records a pending delivery of the event for every active subscription listening to it.
+// Deliveries are persisted before sending so they survive restarts and are delivered at least once.
+func PublishEvent(ctx context.Context, store repositories.Store, event Event) error {
+	subscriptions, err := store.WebhookSubscriptions().List(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			Event:          event.Type,
+			Payload:        payload,
+			Status:         enums.DeliveryPending,
+			NextAttemptAt:  time.Now(),
+		})
+	}
+
+	if err := store.WebhookDeliveries().Create(ctx, deliveries...); err != nil {
+		return err
+	}
+	if len(deliveries) > 0 {
+		wake()
+	}
+	return nil
+}
+
+// Replay schedules a new delivery of the same event payload to the same subscription
+func Replay(ctx context.Context, store repositories.Store, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	original, err := store.WebhookDeliveries().GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         enums.DeliveryPending,
+		NextAttemptAt:  time.Now(),
+		ReplayOfID:     &original.ID,
+	}
+	if err := store.WebhookDeliveries().Create(ctx, replay); err != nil {
+		return nil, err
+	}
+
+	wake()
+	return &replay, nil
+}
+
+// Sign computes the signature sent in the X-Banter-Signature header. Receivers recompute it over
+// the X-Banter-Timestamp header and the raw body to verify the payload.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d.", timestamp)))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func maxAttempts() int {
+	if config.Configs.Webhooks.MaxAttempts <= 0 {
+		return 8
+	}
+	return config.Configs.Webhooks.MaxAttempts
+}
+
+func requestTimeout() time.Duration {
+	if config.Configs.Webhooks.TimeoutInSecs <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(config.Configs.Webhooks.TimeoutInSecs) * time.Second
+}
@@ -0,0 +1,148 @@
+package webhooks
+
+import (
+	"banter/constants/enums"
+	"banter/models"
+	"banter/repositories"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	batchSize    = 50
+	retryDelay   = 10 * time.Second
+	maxRetryWait = time.Hour
+)
+
+var wakeup = make(chan struct{}, 1)
+
+// wake lets the worker know new deliveries are waiting without waiting for the next poll
+func wake() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// StartDeliveryWorker sends due deliveries, retrying failures with exponential backoff. The worker
+// stops once ctx is cancelled and the delivery in flight has been sent.
+func StartDeliveryWorker(ctx context.Context, wg *sync.WaitGroup, store repositories.Store) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		client := &http.Client{Timeout: requestTimeout()}
+		for {
+			deliverDue(ctx, store, client)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-wakeup:
+			}
+		}
+	}()
+}
+
+func deliverDue(ctx context.Context, store repositories.Store, client *http.Client) {
+	deliveries, err := store.WebhookDeliveries().ListDue(ctx, time.Now(), batchSize)
+	if err != nil {
+		slog.Error("Failed to fetch due webhook deliveries", "error", err)
+		return
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Lease the delivery so other replicas skip it while we send
+		leased, err := store.WebhookDeliveries().Lease(ctx, &deliveries[i], time.Now().Add(2*requestTimeout()))
+		if err != nil {
+			slog.Error("Failed to lease webhook delivery", "delivery_id", deliveries[i].ID, "error", err)
+			continue
+		}
+		if !leased {
+			continue
+		}
+
+		// A delivery that was sent is recorded even when the worker is stopping
+		deliver(context.WithoutCancel(ctx), store, client, &deliveries[i])
+	}
+}
+
+func deliver(ctx context.Context, store repositories.Store, client *http.Client, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+
+	subscription, err := store.WebhookSubscriptions().GetByID(ctx, delivery.SubscriptionID)
+	if err != nil || !subscription.IsActive {
+		delivery.Status = enums.DeliveryFailed
+		delivery.LastError = "subscription was removed or disabled"
+	} else {
+		status, err := send(client, subscription, delivery)
+		delivery.ResponseStatus = status
+		if err == nil {
+			now := time.Now()
+			delivery.Status = enums.DeliverySucceeded
+			delivery.DeliveredAt = &now
+			delivery.LastError = ""
+		} else if delivery.Attempts >= maxAttempts() {
+			delivery.Status = enums.DeliveryFailed
+			delivery.LastError = err.Error()
+		} else {
+			// Retry with exponential backoff
+			wait := retryDelay << (delivery.Attempts - 1)
+			if wait > maxRetryWait {
+				wait = maxRetryWait
+			}
+			delivery.NextAttemptAt = time.Now().Add(wait)
+			delivery.LastError = err.Error()
+		}
+	}
+
+	if len(delivery.LastError) > 1024 {
+		delivery.LastError = delivery.LastError[:1024]
+	}
+	if err := store.WebhookDeliveries().Update(ctx, delivery); err != nil {
+		slog.Error("Failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+func send(client *http.Client, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "banter-webhooks")
+	request.Header.Set("X-Banter-Event", string(delivery.Event))
+	request.Header.Set("X-Banter-Event-ID", delivery.EventID.String())
+	request.Header.Set("X-Banter-Delivery", delivery.ID.String())
+	request.Header.Set("X-Banter-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Banter-Signature", Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("endpoint responded with %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
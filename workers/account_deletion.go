@@ -0,0 +1,53 @@
+package workers
+
+import (
+	"banter/repositories"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// StartAccountDeletionWorker periodically anonymises accounts whose deletion grace period has ended
+// until ctx is cancelled
+func StartAccountDeletionWorker(ctx context.Context, wg *sync.WaitGroup, store repositories.Store) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			purgeDeletedAccounts(ctx, store)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func purgeDeletedAccounts(ctx context.Context, store repositories.Store) {
+	users, err := store.Users().ListDueForDeletion(ctx, time.Now())
+	if err != nil {
+		slog.Error("Failed to fetch accounts due for deletion", "error", err)
+		return
+	}
+
+	for _, user := range users {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := RemoveUserDataExports(ctx, store, user.ID); err != nil {
+			slog.Error("Failed to remove exports of user", "user_id", user.ID, "error", err)
+			continue
+		}
+		if err := store.Users().Anonymise(ctx, user.ID); err != nil {
+			slog.Error("Failed to delete account", "user_id", user.ID, "error", err)
+			continue
+		}
+		slog.Info("Deleted account", "user_id", user.ID)
+	}
+}
@@ -0,0 +1,352 @@
+package workers
+
+import (
+	"archive/zip"
+	"banter/constants/enums"
+	"banter/models"
+	"banter/repositories"
+	"banter/stores"
+	"banter/utils/config"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const exportFormatVersion = 1
+
+// exportQueryTimeout replaces the usual query deadline while building an archive, as an export reads a
+// user's whole history at once
+const exportQueryTimeout = 5 * time.Minute
+
+// exportClaimTimeout is how long an export may stay processing before it is taken for abandoned by a
+// worker that stopped, and processed again. It leaves room for every query to run into its timeout.
+const exportClaimTimeout = 30 * time.Minute
+
+var exportQueue = make(chan uuid.UUID, 100)
+
+// exportManifestEntry describes one file inside an export archive
+type exportManifestEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Records     int    `json:"records"`
+}
+
+// exportManifest is written as manifest.json at the root of every export archive
+type exportManifest struct {
+	FormatVersion int                   `json:"format_version"`
+	ExportID      uuid.UUID             `json:"export_id"`
+	UserID        uuid.UUID             `json:"user_id"`
+	GeneratedAt   time.Time             `json:"generated_at"`
+	Files         []exportManifestEntry `json:"files"`
+}
+
+// ExportDirectory returns the directory export archives are written to
+func ExportDirectory() string {
+	if config.Configs.Exports.Directory == "" {
+		return "exports"
+	}
+	return config.Configs.Exports.Directory
+}
+
+// EnqueueDataExport schedules an export for processing. Exports that don't fit in the queue stay
+// pending and are picked up by the next sweep.
+func EnqueueDataExport(id uuid.UUID) {
+	select {
+	case exportQueue <- id:
+	default:
+		slog.Warn("Export queue is full, the export will be picked up by the next sweep", "export_id", id)
+	}
+}
+
+// ExportQueueDepth returns the number of exports queued in this instance
+func ExportQueueDepth() int {
+	return len(exportQueue)
+}
+
+// StartDataExportWorker processes queued exports and periodically retries pending ones, takes back
+// exports abandoned while processing and removes archives whose download window has passed. The user's data is read from the given store.
+// The worker stops once ctx is cancelled, exports still queued stay pending for the next start.
+func StartDataExportWorker(ctx context.Context, wg *sync.WaitGroup, store repositories.Store) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		sweepDataExports(ctx, store)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-exportQueue:
+				ProcessDataExport(context.WithoutCancel(ctx), store, id)
+			case <-ticker.C:
+				sweepDataExports(ctx, store)
+			}
+		}
+	}()
+}
+
+func sweepDataExports(ctx context.Context, store repositories.Store) {
+	released, err := store.DataExports().ReleaseStale(ctx, time.Now().Add(-exportClaimTimeout))
+	if err != nil {
+		slog.Error("Failed to release abandoned exports", "error", err)
+	} else if released > 0 {
+		slog.Warn("Released exports abandoned while processing", "count", released)
+	}
+
+	pending, err := store.DataExports().ListByStatus(ctx, enums.ExportPending)
+	if err != nil {
+		slog.Error("Failed to fetch pending exports", "error", err)
+	} else {
+		for _, export := range pending {
+			if ctx.Err() != nil {
+				return
+			}
+			ProcessDataExport(context.WithoutCancel(ctx), store, export.ID)
+		}
+	}
+
+	expired, err := store.DataExports().ListExpired(ctx, time.Now())
+	if err != nil {
+		slog.Error("Failed to fetch expired exports", "error", err)
+		return
+	}
+	for i := range expired {
+		if err := os.Remove(expired[i].FilePath); err != nil && !os.IsNotExist(err) {
+			slog.Error("Failed to remove export", "export_id", expired[i].ID, "error", err)
+			continue
+		}
+		expired[i].Status = enums.ExportExpired
+		expired[i].FilePath = ""
+		if err := store.DataExports().Update(ctx, &expired[i]); err != nil {
+			slog.Error("Failed to expire export", "export_id", expired[i].ID, "error", err)
+		}
+	}
+}
+
+// ProcessDataExport claims a pending export and writes its archive. Exports already claimed by another
+// worker are left alone.
+func ProcessDataExport(ctx context.Context, store repositories.Store, id uuid.UUID) {
+	claimed, err := store.DataExports().Claim(ctx, id)
+	if err != nil {
+		slog.Error("Failed to claim export", "export_id", id, "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	export, err := store.DataExports().GetByID(ctx, id)
+	if err != nil {
+		slog.Error("Failed to load export", "export_id", id, "error", err)
+		return
+	}
+
+	path, err := buildExportArchive(stores.WithQueryTimeout(ctx, exportQueryTimeout), store, export)
+	now := time.Now()
+	if err != nil {
+		slog.Error("Export failed", "export_id", id, "error", err)
+		export.Status = enums.ExportFailed
+		export.Error = err.Error()
+	} else {
+		expiresAt := now.Add(time.Hour * time.Duration(config.Configs.Exports.ValidityInHrs))
+		export.Status = enums.ExportCompleted
+		export.FilePath = path
+		export.ExpiresAt = &expiresAt
+	}
+	export.CompletedAt = &now
+
+	if err := store.DataExports().Update(ctx, export); err != nil {
+		slog.Error("Failed to update export", "export_id", id, "error", err)
+	}
+}
+
+// buildExportArchive writes the user's profile, conversations, messages and attachments to a ZIP file
+func buildExportArchive(ctx context.Context, store repositories.Store, export *models.DataExport) (string, error) {
+	user, err := store.Users().GetByID(ctx, export.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load user: %w", err)
+	}
+	conversations, err := store.Conversations().ListAllForUser(ctx, export.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load conversations: %w", err)
+	}
+	messages, err := store.Messages().ListBySender(ctx, export.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load messages: %w", err)
+	}
+	messageIDs := make([]uuid.UUID, 0, len(messages))
+	for _, message := range messages {
+		messageIDs = append(messageIDs, message.ID)
+	}
+	attachments, err := store.Attachments().ListByMessageIDs(ctx, messageIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to load attachments: %w", err)
+	}
+
+	if err := os.MkdirAll(ExportDirectory(), 0o750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(ExportDirectory(), export.ID.String()+".zip")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	// No export points at an unfinished archive yet, so nothing else would ever remove it
+	complete := false
+	defer func() {
+		file.Close()
+		if !complete {
+			os.Remove(path)
+		}
+	}()
+
+	archive := zip.NewWriter(file)
+	manifest := exportManifest{
+		FormatVersion: exportFormatVersion,
+		ExportID:      export.ID,
+		UserID:        export.UserID,
+		GeneratedAt:   time.Now(),
+	}
+
+	profile := map[string]interface{}{
+		"id":            user.ID,
+		"username":      user.Username,
+		"email":         user.Email,
+		"first_name":    user.FirstName,
+		"last_name":     user.LastName,
+		"date_of_birth": user.DateOfBirth,
+		"gender":        user.Gender,
+		"mobile_number": user.MobileNumber,
+		"profile_photo": user.ProfilePhotoUrl,
+		"last_seen":     user.LastSeen,
+		"status":        user.Status,
+		"created_at":    user.CreatedAt,
+		"updated_at":    user.UpdatedAt,
+	}
+
+	conversationRecords := make([]map[string]interface{}, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationRecords = append(conversationRecords, map[string]interface{}{
+			"id":         conversation.ID,
+			"name":       conversation.Name,
+			"is_group":   conversation.IsGroup,
+			"created_at": conversation.CreatedAt,
+		})
+	}
+
+	messageRecords := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		messageRecords = append(messageRecords, map[string]interface{}{
+			"id":              message.ID,
+			"conversation_id": message.ConversationID,
+			"content":         message.Content,
+			"created_at":      message.CreatedAt,
+			"updated_at":      message.UpdatedAt,
+		})
+	}
+
+	attachmentRecords := make([]map[string]interface{}, 0, len(attachments))
+	for _, attachment := range attachments {
+		record := map[string]interface{}{
+			"id":         attachment.ID,
+			"message_id": attachment.MessageID,
+			"file_type":  attachment.FileType,
+			"file_url":   attachment.FileUrl,
+			"created_at": attachment.CreatedAt,
+		}
+
+		// Bundle the file itself when it is stored locally
+		archivePath := fmt.Sprintf("attachments/%d_%s", attachment.ID, filepath.Base(attachment.FilePath))
+		if attachment.FilePath != "" {
+			if err := copyFileToArchive(archive, attachment.FilePath, archivePath); err == nil {
+				record["archive_path"] = archivePath
+			}
+		}
+		attachmentRecords = append(attachmentRecords, record)
+	}
+
+	entries := []struct {
+		entry exportManifestEntry
+		data  interface{}
+	}{
+		{exportManifestEntry{"profile.json", "Account profile", 1}, profile},
+		{exportManifestEntry{"conversations.json", "Conversations the user is a member of", len(conversationRecords)}, conversationRecords},
+		{exportManifestEntry{"messages.json", "Messages sent by the user", len(messageRecords)}, messageRecords},
+		{exportManifestEntry{"attachments.json", "Attachments of messages sent by the user", len(attachmentRecords)}, attachmentRecords},
+	}
+	for _, e := range entries {
+		if err := writeJSONToArchive(archive, e.entry.Name, e.data); err != nil {
+			return "", err
+		}
+		manifest.Files = append(manifest.Files, e.entry)
+	}
+
+	if err := writeJSONToArchive(archive, "manifest.json", manifest); err != nil {
+		return "", err
+	}
+	if err := archive.Close(); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	complete = true
+	return path, nil
+}
+
+func writeJSONToArchive(archive *zip.Writer, name string, data interface{}) error {
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func copyFileToArchive(archive *zip.Writer, source, name string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+// RemoveUserDataExports deletes every export archive belonging to a user
+func RemoveUserDataExports(ctx context.Context, store repositories.Store, userID uuid.UUID) error {
+	exports, err := store.DataExports().ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for i := range exports {
+		if exports[i].FilePath != "" {
+			if err := os.Remove(exports[i].FilePath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		exports[i].Status = enums.ExportExpired
+		exports[i].FilePath = ""
+		if err := store.DataExports().Update(ctx, &exports[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}